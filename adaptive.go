@@ -0,0 +1,46 @@
+package simplehealth
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+var errUnderLoad = errors.New("host under load")
+
+// WithAdaptiveInterval makes the runner of Start back off while the host is
+// under load (CheckLoad's threshold), doubling the interval per run up to
+// maxInterval, and skip checks added with Optional. Both reset once the load
+// is back to normal.
+func WithAdaptiveInterval(maxInterval time.Duration) Option {
+	return func(s *SimpleHealth) {
+		s.adaptive = &adaptive{max: maxInterval}
+	}
+}
+
+// Optional marks a check that is skipped while the host is under load with
+// WithAdaptiveInterval.
+func Optional() CheckOption {
+	return func(c *check) {
+		c.optional = true
+	}
+}
+
+type adaptive struct {
+	max      time.Duration
+	pressure atomic.Bool
+}
+
+// next measures the load and returns the interval until the next run.
+func (a *adaptive) next(interval, last time.Duration) time.Duration {
+	load, err := measureLoad()
+	a.pressure.Store(err == nil && load > maxLoad)
+	if !a.pressure.Load() {
+		return interval
+	}
+	return min(max(interval, 2*last), max(interval, a.max))
+}
+
+func (s *SimpleHealth) underLoad() bool {
+	return s.adaptive != nil && s.adaptive.pressure.Load()
+}
@@ -0,0 +1,348 @@
+package simplehealth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultStaleAfter  = 5 * time.Minute
+	defaultForgetAfter = 7 * 24 * time.Hour
+	maxPushSize        = 4 << 20
+)
+
+// Aggregator receives the reports of Pusher from many hosts and serves a
+// fleet wide rollup. The zero value is ready to use:
+//
+//	agg := &simplehealth.Aggregator{Token: "secret"}
+//	http.ListenAndServe(":8080", agg.Handler())
+type Aggregator struct {
+	// StaleAfter marks a host stale when no report arrived for this long.
+	// The default is 3 times the interval the host reports, or 5 minutes
+	// for hosts that push without Start.
+	StaleAfter time.Duration
+	// ForgetAfter removes a host when no report arrived for this long,
+	// 7 days by default, negative to keep hosts until they are deleted with
+	// DELETE /push?host=name.
+	ForgetAfter time.Duration
+	// Token, if set, must be sent as bearer token with every push.
+	Token string
+	// SnapshotPath, if set, keeps the hosts across restarts. Restored hosts
+	// are served as stale until they push again, so dashboards do not blank
+	// out while the aggregator is redeployed.
+	SnapshotPath string
+	Clock        Clock
+
+	once    sync.Once
+	loadErr error
+	mu      sync.Mutex
+	hosts   map[string]*fleetHost
+}
+
+type fleetHost struct {
+	report   PushedReport
+	checks   json.RawMessage
+	failing  []string
+	received time.Time
+	restored bool
+}
+
+// savedHost is a fleetHost in the snapshot.
+type savedHost struct {
+	Report   PushedReport    `json:"report"`
+	Checks   json.RawMessage `json:"checks,omitempty"`
+	Failing  []string        `json:"failing,omitempty"`
+	Received time.Time       `json:"received"`
+}
+
+// load restores the snapshot on first use.
+func (a *Aggregator) load() error {
+	a.once.Do(func() {
+		if a.SnapshotPath == "" {
+			return
+		}
+		data, err := os.ReadFile(a.SnapshotPath)
+		if errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		var saved map[string]savedHost
+		if err == nil {
+			err = json.Unmarshal(data, &saved)
+		}
+		if err != nil {
+			a.loadErr = fmt.Errorf("cannot load fleet snapshot: %w", err)
+			return
+		}
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.hosts == nil {
+			a.hosts = make(map[string]*fleetHost)
+		}
+		for name, h := range saved {
+			a.hosts[name] = &fleetHost{report: h.Report, checks: h.Checks, failing: h.Failing, received: h.Received, restored: true}
+		}
+	})
+	return a.loadErr
+}
+
+// save writes the snapshot, must be called with a.mu held.
+func (a *Aggregator) save() error {
+	if a.SnapshotPath == "" {
+		return nil
+	}
+	saved := make(map[string]savedHost, len(a.hosts))
+	for name, h := range a.hosts {
+		saved[name] = savedHost{Report: h.report, Checks: h.checks, Failing: h.failing, Received: h.received}
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(a.SnapshotPath, data)
+}
+
+// FleetHost is the state of one host in the rollup.
+type FleetHost struct {
+	Host     string            `json:"host"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Status   string            `json:"status"`
+	Failing  []string          `json:"failing,omitempty"`
+	LastSeen time.Time         `json:"last_seen"`
+	Age      float64           `json:"age_seconds"`
+	Checks   json.RawMessage   `json:"checks,omitempty"`
+}
+
+// Fleet is the rollup of all hosts, sorted by name.
+type Fleet struct {
+	Total     int         `json:"total"`
+	Healthy   int         `json:"healthy"`
+	Unhealthy int         `json:"unhealthy"`
+	Stale     int         `json:"stale"`
+	Hosts     []FleetHost `json:"hosts"`
+}
+
+// Handler serves PushHandler under /push and FleetHandler under /fleet.
+func (a *Aggregator) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/push", a.PushHandler)
+	mux.HandleFunc("/fleet", a.FleetHandler)
+	return mux
+}
+
+// PushHandler accepts a PushedReport as JSON body of a POST. A DELETE with
+// ?host=name removes a decommissioned host.
+func (a *Aggregator) PushHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if a.Token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(a.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="simplehealth"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+	}
+
+	if r.Method == http.MethodDelete {
+		a.delete(w, r.URL.Query().Get("host"))
+		return
+	}
+
+	// CheckResult only marshals, so the checks are kept as sent
+	var body struct {
+		PushedReport
+		Checks json.RawMessage `json:"checks"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPushSize)).Decode(&body); err != nil {
+		http.Error(w, "invalid report: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := a.load(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if body.Host == "" {
+		http.Error(w, "invalid report: no host", http.StatusBadRequest)
+		return
+	}
+	var checks []struct {
+		Name   string `json:"name"`
+		Status Status `json:"status"`
+	}
+	if err := json.Unmarshal(body.Checks, &checks); len(body.Checks) > 0 && err != nil {
+		http.Error(w, "invalid report: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h := &fleetHost{
+		report:   body.PushedReport,
+		checks:   body.Checks,
+		received: orSystemClock(a.Clock).Now(),
+	}
+	for _, c := range checks {
+		if c.Status == StatusFail {
+			h.failing = append(h.failing, c.Name)
+		}
+	}
+
+	a.mu.Lock()
+	if a.hosts == nil {
+		a.hosts = make(map[string]*fleetHost)
+	}
+	a.hosts[body.Host] = h
+	a.forget(h.received)
+	err := a.save()
+	a.mu.Unlock()
+	if err != nil {
+		http.Error(w, "cannot save fleet snapshot: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *Aggregator) delete(w http.ResponseWriter, host string) {
+	if host == "" {
+		http.Error(w, "no host", http.StatusBadRequest)
+		return
+	}
+	if err := a.load(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	a.mu.Lock()
+	_, ok := a.hosts[host]
+	delete(a.hosts, host)
+	err := a.save()
+	a.mu.Unlock()
+	switch {
+	case !ok:
+		http.Error(w, "unknown host "+host, http.StatusNotFound)
+	case err != nil:
+		http.Error(w, "cannot save fleet snapshot: "+err.Error(), http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// forget removes the hosts silent for longer than ForgetAfter and reports
+// whether there were any, must be called with a.mu held.
+func (a *Aggregator) forget(now time.Time) bool {
+	forgetAfter := a.ForgetAfter
+	if forgetAfter == 0 {
+		forgetAfter = defaultForgetAfter
+	}
+	if forgetAfter < 0 {
+		return false
+	}
+	forgot := false
+	for name, h := range a.hosts {
+		if now.Sub(h.received) > forgetAfter {
+			delete(a.hosts, name)
+			forgot = true
+		}
+	}
+	return forgot
+}
+
+// Fleet returns the rollup, with the checks of every host if verbose.
+func (a *Aggregator) Fleet(verbose bool) Fleet {
+	_ = a.load()
+	now := orSystemClock(a.Clock).Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.forget(now) {
+		// a failed save is retried with the next push, restored hosts are
+		// forgotten again
+		_ = a.save()
+	}
+
+	f := Fleet{Total: len(a.hosts), Hosts: make([]FleetHost, 0, len(a.hosts))}
+	for name, h := range a.hosts {
+		fh := FleetHost{
+			Host:     name,
+			Labels:   h.report.Labels,
+			Failing:  h.failing,
+			LastSeen: h.received,
+			Age:      now.Sub(h.received).Seconds(),
+		}
+		switch {
+		case h.restored || now.Sub(h.received) > a.staleAfter(h):
+			fh.Status = "stale"
+			f.Stale++
+		case h.report.Healthy:
+			fh.Status = stateName(true)
+			f.Healthy++
+		default:
+			fh.Status = stateName(false)
+			f.Unhealthy++
+		}
+		if verbose {
+			fh.Checks = h.checks
+		}
+		f.Hosts = append(f.Hosts, fh)
+	}
+	sort.Slice(f.Hosts, func(i, j int) bool { return f.Hosts[i].Host < f.Hosts[j].Host })
+	return f
+}
+
+func (a *Aggregator) staleAfter(h *fleetHost) time.Duration {
+	switch {
+	case a.StaleAfter > 0:
+		return a.StaleAfter
+	case h.report.Interval > 0:
+		return 3 * time.Duration(h.report.Interval*float64(time.Second))
+	}
+	return defaultStaleAfter
+}
+
+// FleetHandler serves the rollup as JSON, or MessagePack when accepted, with
+// ?verbose=1 including the checks of every host. It answers 503 when any host
+// is unhealthy or stale: a host that stops reporting is an outage until it
+// is forgotten after ForgetAfter or deleted. ?fields, ?offset and ?limit
+// select hosts like checks in Handler.
+func (a *Aggregator) FleetHandler(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := a.load(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, verbose := verbosity(r, false)
+	f := a.Fleet(verbose)
+
+	code := http.StatusOK
+	if f.Unhealthy > 0 || f.Stale > 0 {
+		code = http.StatusServiceUnavailable
+	}
+	if q.empty() {
+		writeBody(w, r, accepted(r), code, f, false)
+		return
+	}
+	hosts, _, err := q.page(f.Hosts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	q.pick(hosts)
+	writeBody(w, r, accepted(r), code, map[string]any{
+		"total":     f.Total,
+		"healthy":   f.Healthy,
+		"unhealthy": f.Unhealthy,
+		"stale":     f.Stale,
+		"hosts":     hosts,
+	}, false)
+}
@@ -0,0 +1,155 @@
+package simplehealth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// credentials accepted by the handlers, see WithBearerToken and
+// WithBasicAuth.
+type credentials struct {
+	bearer []string
+	basic  [][2]string
+}
+
+// WithBearerToken requires "Authorization: Bearer t" for detailed output.
+// Requests without credentials still get the terse status from Handler, so
+// load balancers keep working, the other handlers answer 401.
+func WithBearerToken(t string) Option {
+	return func(s *SimpleHealth) {
+		s.auth.bearer = append(s.auth.bearer, t)
+	}
+}
+
+// WithBasicAuth is WithBearerToken with HTTP basic authentication.
+func WithBasicAuth(user, password string) Option {
+	return func(s *SimpleHealth) {
+		s.auth.basic = append(s.auth.basic, [2]string{user, password})
+	}
+}
+
+// WithAllowedCIDRs limits detailed output to clients in the given networks,
+// e.g. "10.0.0.0/8", "127.0.0.1/32". Others get the terse status from
+// Handler, the other handlers answer 403. The client is the peer address of
+// the connection, X-Forwarded-For is not trusted. Clients of a unix socket
+// have no address and are always allowed, WithSocketMode controls who can
+// connect.
+func WithAllowedCIDRs(cidrs ...string) Option {
+	return func(s *SimpleHealth) {
+		for _, cidr := range cidrs {
+			prefix, err := netip.ParsePrefix(cidr)
+			if err != nil {
+				s.configErrs = append(s.configErrs, fmt.Errorf("WithAllowedCIDRs: %w", err))
+				continue
+			}
+			s.allowed = append(s.allowed, prefix.Masked())
+		}
+	}
+}
+
+// allowedClient reports whether the peer of r is in one of the allowed
+// networks, or true when none are configured or r came in on a unix socket.
+func (s *SimpleHealth) allowedClient(r *http.Request) bool {
+	if len(s.allowed) == 0 {
+		return true
+	}
+	if r == nil {
+		return false
+	}
+	if _, ok := r.Context().Value(http.LocalAddrContextKey).(*net.UnixAddr); ok {
+		return true
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range s.allowed {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c credentials) enabled() bool {
+	return len(c.bearer) > 0 || len(c.basic) > 0
+}
+
+// authorize reports whether r may see details, and whether it sent
+// credentials at all.
+func (c credentials) authorize(r *http.Request) (ok, sent bool) {
+	if !c.enabled() {
+		return true, false
+	}
+	if r == nil || r.Header.Get("Authorization") == "" {
+		return false, false
+	}
+	if user, password, isBasic := r.BasicAuth(); isBasic {
+		for _, b := range c.basic {
+			if equal(user, b[0]) && equal(password, b[1]) {
+				return true, true
+			}
+		}
+		return false, true
+	}
+	if token, isBearer := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); isBearer {
+		for _, t := range c.bearer {
+			if equal(token, t) {
+				return true, true
+			}
+		}
+	}
+	return false, true
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// unauthorized answers 401 with a challenge for the configured schemes.
+func (c credentials) unauthorized(w http.ResponseWriter) {
+	if len(c.basic) > 0 {
+		w.Header().Add("WWW-Authenticate", `Basic realm="simplehealth"`)
+	}
+	if len(c.bearer) > 0 {
+		w.Header().Add("WWW-Authenticate", `Bearer realm="simplehealth"`)
+	}
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// requireAuth answers 403 or 401 unless r may see details and reports
+// whether it did.
+func (s *SimpleHealth) requireAuth(w http.ResponseWriter, r *http.Request) bool {
+	if !s.allowedClient(r) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return true
+	}
+	if ok, _ := s.auth.authorize(r); ok {
+		return false
+	}
+	s.auth.unauthorized(w)
+	return true
+}
+
+// Protect wraps h with the access control of the detail handlers, see
+// WithBearerToken, WithBasicAuth and WithAllowedCIDRs, e.g. for the debug
+// handlers:
+//
+//	mux.Handle("/health/debug/", s.Protect(debug.Handler("/health/debug")))
+func (s *SimpleHealth) Protect(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.requireAuth(w, r) {
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,46 @@
+package simplehealth
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowedClient(t *testing.T) {
+	unix := httptest.NewRequest(http.MethodGet, "/", nil)
+	unix.RemoteAddr = "@"
+	unix = unix.WithContext(context.WithValue(unix.Context(), http.LocalAddrContextKey, &net.UnixAddr{Name: "/run/simplehealth.sock", Net: "unix"}))
+
+	tests := []struct {
+		name   string
+		cidrs  []string
+		remote string
+		req    *http.Request
+		want   bool
+	}{
+		{"no CIDRs", nil, "192.0.2.1:1234", nil, true},
+		{"inside", []string{"10.0.0.0/8"}, "10.1.2.3:1234", nil, true},
+		{"outside", []string{"10.0.0.0/8"}, "192.0.2.1:1234", nil, false},
+		{"second CIDR", []string{"10.0.0.0/8", "192.0.2.0/24"}, "192.0.2.1:1234", nil, true},
+		{"mapped IPv4", []string{"127.0.0.1/32"}, "[::ffff:127.0.0.1]:1234", nil, true},
+		{"IPv6", []string{"::1/128"}, "[::1]:1234", nil, true},
+		{"no port", []string{"10.0.0.0/8"}, "10.1.2.3", nil, true},
+		{"unparsable", []string{"10.0.0.0/8"}, "garbage", nil, false},
+		{"unix socket", []string{"10.0.0.0/8"}, "", unix, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSimpleHealth(WithAllowedCIDRs(tt.cidrs...))
+			r := tt.req
+			if r == nil {
+				r = httptest.NewRequest(http.MethodGet, "/", nil)
+				r.RemoteAddr = tt.remote
+			}
+			if got := s.allowedClient(r); got != tt.want {
+				t.Errorf("allowedClient(%s) = %v, want %v", r.RemoteAddr, got, tt.want)
+			}
+		})
+	}
+}
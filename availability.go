@@ -0,0 +1,108 @@
+package simplehealth
+
+import (
+	"math"
+	"time"
+)
+
+// availabilityWindows are the rolling windows reported in verbose output.
+var availabilityWindows = []struct {
+	name string
+	d    time.Duration
+}{
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+}
+
+// uptime counts passed and total runs in minute buckets for an hour and hour
+// buckets for a week.
+type uptime struct {
+	minutes, hours []bucket
+}
+
+type bucket struct {
+	start     time.Time
+	up, total int
+}
+
+// countUptime must be called with s.mu held. The aggregate is stored under
+// the empty name.
+func (s *SimpleHealth) countUptime(name string, ok bool, now time.Time) {
+	if s.availability == nil {
+		s.availability = make(map[string]*uptime)
+	}
+	u := s.availability[name]
+	if u == nil {
+		u = &uptime{}
+		s.availability[name] = u
+	}
+	u.minutes = addBucket(u.minutes, now.Truncate(time.Minute), ok, 60)
+	u.hours = addBucket(u.hours, now.Truncate(time.Hour), ok, 7*24)
+}
+
+func addBucket(buckets []bucket, start time.Time, ok bool, keep int) []bucket {
+	if n := len(buckets); n == 0 || !buckets[n-1].start.Equal(start) {
+		buckets = append(buckets, bucket{start: start})
+	}
+	b := &buckets[len(buckets)-1]
+	b.total++
+	if ok {
+		b.up++
+	}
+	return buckets[max(0, len(buckets)-keep):]
+}
+
+// percent returns the availability over window, or false when the check did
+// not run in it.
+func (u *uptime) percent(now time.Time, window time.Duration) (float64, bool) {
+	buckets, size := u.hours, time.Hour
+	if window <= time.Hour {
+		buckets, size = u.minutes, time.Minute
+	}
+	since := now.Add(-window)
+	var up, total int
+	for _, b := range buckets {
+		if b.start.Add(size).After(since) {
+			up += b.up
+			total += b.total
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return math.Round(10000*float64(up)/float64(total)) / 100, true
+}
+
+func (u *uptime) report(now time.Time) map[string]float64 {
+	m := make(map[string]float64, len(availabilityWindows))
+	for _, w := range availabilityWindows {
+		if p, ok := u.percent(now, w.d); ok {
+			m[w.name] = p
+		}
+	}
+	return m
+}
+
+// availabilityReport returns the overall and per-check availability in
+// percent over the last hour, day and week.
+func (s *SimpleHealth) availabilityReport() map[string]any {
+	now := orSystemClock(s.clock).Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	overall, ok := s.availability[""]
+	if !ok {
+		return nil
+	}
+	checks := make(map[string]map[string]float64, len(s.availability)-1)
+	for name, u := range s.availability {
+		if name != "" {
+			checks[name] = u.report(now)
+		}
+	}
+	return map[string]any{
+		"overall": overall.report(now),
+		"checks":  checks,
+	}
+}
@@ -0,0 +1,54 @@
+package simplehealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+	"time"
+)
+
+type budget struct {
+	cpu time.Duration
+	mem uint64
+}
+
+// WithBudget fails the check when a single run uses more than cpu time or
+// allocates more than mem bytes, so the monitoring itself can't quietly harm
+// a loaded host. Go cannot preempt a running check, so it completes and is
+// flagged afterwards. Allocations are counted process wide and include
+// concurrently running checks. Zero disables a limit.
+func WithBudget(cpu time.Duration, mem uint64) CheckOption {
+	return func(c *check) {
+		c.budget = &budget{cpu: cpu, mem: mem}
+	}
+}
+
+func (c *check) runWithBudget(ctx context.Context) (any, error) {
+	// pin the goroutine so thread CPU time is attributable to this check
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	cpu0, mem0 := threadCPUTime(), heapAllocs()
+	value, err := c.call(ctx)
+	cpu, mem := threadCPUTime()-cpu0, heapAllocs()-mem0
+
+	errs := []error{err}
+	if c.budget.cpu > 0 && cpu > c.budget.cpu {
+		errs = append(errs, fmt.Errorf("%s exceeded its cpu budget: used %s, budget %s", c.name, cpu, c.budget.cpu))
+	}
+	if c.budget.mem > 0 && mem > c.budget.mem {
+		errs = append(errs, fmt.Errorf("%s exceeded its memory budget: allocated %d bytes, budget %d", c.name, mem, c.budget.mem))
+	}
+	return value, errors.Join(errs...)
+}
+
+func heapAllocs() uint64 {
+	sample := []metrics.Sample{{Name: "/gc/heap/allocs:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
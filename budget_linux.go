@@ -0,0 +1,15 @@
+package simplehealth
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func threadCPUTime() time.Duration {
+	var ru unix.Rusage
+	if err := unix.Getrusage(unix.RUSAGE_THREAD, &ru); err != nil {
+		return 0
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+}
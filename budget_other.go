@@ -0,0 +1,17 @@
+//go:build !linux && !windows
+
+package simplehealth
+
+import (
+	"syscall"
+	"time"
+)
+
+// threadCPUTime falls back to the CPU time of the process.
+func threadCPUTime() time.Duration {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return 0
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+}
@@ -0,0 +1,95 @@
+package simplehealth
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// AddChecks adds the Checkers under their map keys, in sorted order. Unlike
+// Add it rejects nil checkers and names that are already taken, and returns
+// all such errors together with those of Validate.
+func (s *SimpleHealth) AddChecks(checks map[string]Checker, opts ...CheckOption) error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(checks)) {
+		errs = append(errs, s.addNamed(name, checks[name], opts...))
+	}
+	return errors.Join(append(errs, s.Validate())...)
+}
+
+func (s *SimpleHealth) addNamed(name string, c Checker, opts ...CheckOption) error {
+	if err := s.checkName(name, c == nil); err != nil {
+		return err
+	}
+	s.addChecker(name, c, opts...)
+	return nil
+}
+
+func (s *SimpleHealth) checkName(name string, isNil bool) error {
+	switch {
+	case name == "":
+		return errors.New("check without name")
+	case isNil:
+		return fmt.Errorf("%s: nil check", name)
+	case slices.ContainsFunc(s.checkList(), func(c check) bool { return c.name == name }):
+		return fmt.Errorf("%s: duplicate check name", name)
+	}
+	return nil
+}
+
+// CheckFunc adapts a check function to a Checker.
+type CheckFunc func() error
+
+func (f CheckFunc) Check() error {
+	return f()
+}
+
+// Builder registers many checks and reports all configuration errors at once
+// from Build, e.g.
+//
+//	s, err := simplehealth.NewBuilder(simplehealth.WithLogger(l)).
+//		Check("disk", simplehealth.NewDiskCheck()).
+//		Func("api", checkAPI, simplehealth.DependsOn("disk")).
+//		Build()
+type Builder struct {
+	s    *SimpleHealth
+	errs []error
+}
+
+// NewBuilder starts without the default checks.
+func NewBuilder(opts ...Option) *Builder {
+	s := NewSimpleHealth(opts...)
+	s.SetChecks()
+	return &Builder{s: s}
+}
+
+func (b *Builder) Check(name string, c Checker, opts ...CheckOption) *Builder {
+	if err := b.s.addNamed(name, c, opts...); err != nil {
+		b.errs = append(b.errs, err)
+	}
+	return b
+}
+
+func (b *Builder) Func(name string, fn func() error, opts ...CheckOption) *Builder {
+	if err := b.s.checkName(name, fn == nil); err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	b.s.addFunc(name, fn, opts...)
+	return b
+}
+
+func (b *Builder) Checks(checks map[string]Checker, opts ...CheckOption) *Builder {
+	for _, name := range slices.Sorted(maps.Keys(checks)) {
+		b.Check(name, checks[name], opts...)
+	}
+	return b
+}
+
+// Build returns the SimpleHealth with the registration errors, invalid
+// options and the errors of Validate joined. The SimpleHealth is usable
+// either way, failing its runs on invalid options.
+func (b *Builder) Build() (*SimpleHealth, error) {
+	return b.s, errors.Join(append(slices.Clone(b.errs), b.s.Validate())...)
+}
@@ -0,0 +1,78 @@
+package simplehealth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Role bundles of built-in checks, selectable by name with WithRole.
+var roles = map[string]func() []Checker{
+	"webserver": WebServerChecks,
+	"database":  DatabaseServerChecks,
+	"worker":    WorkerNodeChecks,
+}
+
+func WebServerChecks() []Checker {
+	return []Checker{
+		CheckFunc(CheckOpenFiles),
+		CheckFunc(CheckDisk),
+		CheckFunc(CheckLoad),
+		CheckSystemFDs(0.8),
+		CheckConntrack(0.8),
+		CheckFilesystemWritable(),
+		CheckOOMKills(time.Hour),
+	}
+}
+
+func DatabaseServerChecks() []Checker {
+	return []Checker{
+		CheckFunc(CheckOpenFiles),
+		CheckFunc(CheckDisk),
+		CheckFunc(CheckLoad),
+		CheckSystemFDs(0.8),
+		CheckFilesystemWritable(),
+		CheckFunc(CheckMDRaid),
+		CheckOOMKills(24 * time.Hour),
+	}
+}
+
+func WorkerNodeChecks() []Checker {
+	return []Checker{
+		CheckFunc(CheckDisk),
+		CheckFunc(CheckLoad),
+		CheckSystemFDs(0.9),
+		CheckFilesystemWritable(),
+		CheckOOMKills(time.Hour),
+	}
+}
+
+func ChecksForRole(role string) ([]Checker, error) {
+	bundle, ok := roles[role]
+	if !ok {
+		names := make([]string, 0, len(roles))
+		for name := range roles {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return nil, fmt.Errorf("unknown role %q, want one of %s", role, strings.Join(names, ", "))
+	}
+	return bundle(), nil
+}
+
+// WithRole replaces the checks with the bundle for role, e.g. "webserver".
+// An unknown role is reported as a failing check.
+func WithRole(role string) Option {
+	return func(s *SimpleHealth) {
+		checks, err := ChecksForRole(role)
+		if err != nil {
+			s.configErrs = append(s.configErrs, err)
+			return
+		}
+		s.SetChecks()
+		for _, c := range checks {
+			s.Add(c)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package simplehealth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+)
+
+// CanaryCheck compares the values measured by the checks of two simplehealth
+// endpoints during a progressive rollout. It fails when a check on the canary
+// exceeds the same check on the baseline by more than its tolerance, a
+// fraction keyed by check name, e.g. {"CheckLoad": 0.25} allows a 25% higher
+// load. A baseline of 0 makes the tolerance absolute, so {"ErrorRate": 0.01}
+// allows the canary an error rate of 1% while the baseline has none. Add it
+// with SimpleHealth.Add.
+type CanaryCheck struct {
+	CanaryURL   string
+	BaselineURL string
+	Tolerances  map[string]float64
+	Client      *http.Client
+}
+
+func (c *CanaryCheck) Check() error {
+	canary, err := c.values(c.CanaryURL)
+	if err != nil {
+		return fmt.Errorf("canary: %w", err)
+	}
+	baseline, err := c.values(c.BaselineURL)
+	if err != nil {
+		return fmt.Errorf("baseline: %w", err)
+	}
+
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(c.Tolerances)) {
+		got, ok := canary[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("canary does not report a value for %s", name))
+			continue
+		}
+		want, ok := baseline[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("baseline does not report a value for %s", name))
+			continue
+		}
+		tolerance := c.Tolerances[name]
+		limit := want + math.Abs(want)*tolerance
+		if want == 0 {
+			// no relative headroom above 0, e.g. an error rate
+			limit = tolerance
+		}
+		if got > limit {
+			errs = append(errs, fmt.Errorf("canary %s is %.4g, baseline %.4g, want at most %.4g", name, got, want, limit))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *CanaryCheck) Validate() error {
+	for _, u := range []string{c.CanaryURL, c.BaselineURL} {
+		if _, err := url.ParseRequestURI(u); err != nil {
+			return err
+		}
+	}
+	if len(c.Tolerances) == 0 {
+		return errors.New("no tolerances configured")
+	}
+	return nil
+}
+
+// values returns the numeric values of the verbose response of endpoint by
+// check name.
+func (c *CanaryCheck) values(endpoint string) (map[string]float64, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("verbose", "1")
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultChildTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Checks []struct {
+			Name  string `json:"name"`
+			Value any    `json:"value"`
+		} `json:"checks"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	values := make(map[string]float64, len(body.Checks))
+	for _, check := range body.Checks {
+		if v, ok := check.Value.(float64); ok {
+			values[check.Name] = v
+		}
+	}
+	return values, nil
+}
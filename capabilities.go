@@ -0,0 +1,106 @@
+package simplehealth
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Capability tells whether the data source of a built-in check is usable on
+// this host, and if not why, so checks do not silently pass as no-ops.
+type Capability struct {
+	Name      string `json:"name"`
+	Checks    string `json:"checks"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// WithCapabilityReport logs the Capabilities once on construction, needs
+// WithLogger.
+func WithCapabilityReport() Option {
+	return func(s *SimpleHealth) {
+		s.reportCapabilities = true
+	}
+}
+
+var capabilityProbes = []struct {
+	name, checks string
+	probe        func() error
+}{
+	{"load average", "CheckLoad", func() error { _, err := measureLoad(); return ignoreCheckFailure(err) }},
+	{"process list", "CheckOpenFiles, CheckProcessOpenFiles", func() error { _, err := process.Processes(); return err }},
+	{"open files limits", "CheckOpenFiles, CheckOwnOpenFiles", func() error {
+		p, err := process.NewProcess(int32(os.Getpid()))
+		if err != nil {
+			return err
+		}
+		_, _, err = openFilesUsage(p)
+		return err
+	}},
+	{"partitions", "CheckDisk, CheckDiskPredict, CheckFilesystemWritable", func() error { _, err := disk.Partitions(false); return err }},
+	{"kernel log", "CheckOOMKills", func() error {
+		if fd, err := syscall.Open(kmsgPath, syscall.O_RDONLY|syscall.O_NONBLOCK, 0); err == nil {
+			syscall.Close(fd)
+			return nil
+		}
+		_, err := cgroupOOMKills()
+		return err
+	}},
+	{"file handles", "CheckSystemFDs", readable(fileNrPath)},
+	{"entropy pool", "CheckEntropy", readable(entropyPath)},
+	{"conntrack", "CheckConntrack", readable(conntrackCountPath)},
+	{"mdstat", "CheckMDRaid", readable(mdstatPath)},
+	{"diskstats", "CheckDiskLatency", readable(diskstatsPath)},
+	{"pressure stall information", "CheckPressure", func() error { _, _, err := readPressure("cpu"); return err }},
+	{"dhclient leases", "CheckDHCPLease", func() error { _, err := readDHCPLeases(); return err }},
+	{"systemctl", "CheckCrashLoop", func() error { _, err := exec.LookPath(systemctlPath); return err }},
+	{"smartctl", "CheckSMART", func() error { _, err := exec.LookPath(smartctlPath); return err }},
+}
+
+func readable(path string) func() error {
+	return func() error {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	}
+}
+
+// ignoreCheckFailure keeps threshold failures from counting as unavailable.
+func ignoreCheckFailure(err error) error {
+	if errors.Is(err, ErrUnavailable) {
+		return err
+	}
+	return nil
+}
+
+// Capabilities probes the data sources of the built-in checks.
+func Capabilities() []Capability {
+	caps := make([]Capability, len(capabilityProbes))
+	for i, p := range capabilityProbes {
+		caps[i] = Capability{Name: p.name, Checks: p.checks, Available: true}
+		if err := p.probe(); err != nil {
+			caps[i].Available = false
+			caps[i].Reason = err.Error()
+		}
+	}
+	return caps
+}
+
+func (s *SimpleHealth) logCapabilities() {
+	if s.logger == nil {
+		return
+	}
+	for _, c := range Capabilities() {
+		if c.Available {
+			s.logger.Info("capability available", "capability", c.Name, "checks", c.Checks)
+		} else {
+			s.logger.Warn("capability unavailable", "capability", c.Name, "checks", c.Checks, "reason", c.Reason)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package simplehealth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// capacityMemoryLimit is the memory usage counted as no headroom left.
+const capacityMemoryLimit = 0.9
+
+type capacityDimension struct {
+	name    string
+	measure func() (float64, error)
+	limit   float64
+}
+
+var capacityDimensions = []capacityDimension{
+	{"load", measureLoad, maxLoad},
+	{"memory", measureMemory, capacityMemoryLimit},
+	{"open_files", measureOpenFiles, maxOpenFilesPerc},
+	{"disk", measureDisk, maxDiskPerc},
+}
+
+// WithCapacityWeights turns the score of CapacityHandler into the weighted
+// mean of the dimensions "load", "memory", "open_files" and "disk" instead of
+// the lowest one, e.g. {"disk": 3} for an IO bound service. Dimensions that
+// are not listed weigh 1, a weight of 0 leaves one out.
+func WithCapacityWeights(weights map[string]float64) Option {
+	return func(s *SimpleHealth) {
+		for name, w := range weights {
+			if !slices.ContainsFunc(capacityDimensions, func(d capacityDimension) bool { return d.name == name }) || w < 0 {
+				s.configErrs = append(s.configErrs, fmt.Errorf("invalid capacity weight %s: %g", name, w))
+			}
+		}
+		s.capacityWeights = weights
+	}
+}
+
+func (s *SimpleHealth) capacityWeight(name string) float64 {
+	if w, ok := s.capacityWeights[name]; ok {
+		return w
+	}
+	return 1
+}
+
+// withCapacity lets CapacityHandler reuse the value of a check of dimension.
+func withCapacity(dimension string, limit float64) CheckOption {
+	return func(c *check) {
+		c.capacity = dimension
+		c.threshold = limit
+	}
+}
+
+// measuredScore is the lowest score of the checks of the last run that
+// measured dimension.
+func measuredScore(dimension string, last *[]CheckResult) (score float64, ok bool) {
+	if last == nil {
+		return 0, false
+	}
+	for _, r := range *last {
+		usage, isValue := r.Value.(float64)
+		limit, isLimit := r.threshold.(float64)
+		if r.capacity != dimension || !isValue || !isLimit || limit <= 0 || r.Status == StatusSkip || r.Status == StatusNotApplicable {
+			continue
+		}
+		if sc := capacityScore(usage, limit); !ok || sc < score {
+			score, ok = sc, true
+		}
+	}
+	return score, ok
+}
+
+func capacityScore(usage, limit float64) float64 {
+	return math.Round(100 * math.Max(0, 1-usage/limit))
+}
+
+func measureOwnOpenFiles() (float64, error) {
+	p, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return 0, unavailable("open files", err)
+	}
+	_, usage, err := openFilesUsage(p)
+	return usage, err
+}
+
+func measureMemory() (float64, error) {
+	v, err := mem.VirtualMemory()
+	if err != nil {
+		return 0, unavailable("memory", err)
+	}
+	if v.Total == 0 {
+		return 0, unavailable("memory", errors.New("total is 0"))
+	}
+	return 1 - float64(v.Available)/float64(v.Total), nil
+}
+
+// CapacityHandler serves a capacity score from 0 (no headroom) to 100 for
+// autoscalers and schedulers, e.g. mounted at /health/capacity. Every
+// dimension scores the headroom below the threshold of its check: the value
+// and threshold of the configured CheckLoad, CheckOpenFiles, CheckDisk,
+// *LoadCheck or *DiskCheck of the last run, else a fresh measurement against
+// the defaults. With WithLowFootprint open files only counts this process.
+// The score is the lowest of them or their WithCapacityWeights mean:
+//
+//	{"capacity": 35, "dimensions": {"load": 80, "memory": 35, ...}}
+//
+// Dimensions that cannot be measured are listed under "unavailable". When
+// none can, it answers 503.
+func (s *SimpleHealth) CapacityHandler(w http.ResponseWriter, r *http.Request) {
+	if s.requireAuth(w, r) {
+		return
+	}
+	scores := make([]float64, len(capacityDimensions))
+	errs := make([]error, len(capacityDimensions))
+	last := s.runs.last.Load()
+	var wg sync.WaitGroup
+	for i, d := range capacityDimensions {
+		if score, ok := measuredScore(d.name, last); ok {
+			scores[i] = score
+			continue
+		}
+		measure := d.measure
+		if d.name == "open_files" && s.lowFootprint {
+			measure = measureOwnOpenFiles
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			usage, err := measure()
+			if errors.Is(err, ErrUnavailable) || errors.Is(err, ErrNotApplicable) {
+				errs[i] = err
+				return
+			}
+			scores[i] = capacityScore(usage, d.limit)
+		}()
+	}
+	wg.Wait()
+
+	capacity := -1.0
+	var weighted, total float64
+	dimensions := make(map[string]float64)
+	unavailable := make(map[string]string)
+	for i, d := range capacityDimensions {
+		if errs[i] != nil {
+			unavailable[d.name] = errs[i].Error()
+			continue
+		}
+		dimensions[d.name] = scores[i]
+		if w := s.capacityWeight(d.name); w > 0 {
+			weighted += w * scores[i]
+			total += w
+			if capacity < 0 || scores[i] < capacity {
+				capacity = scores[i]
+			}
+		}
+	}
+	if s.capacityWeights != nil && total > 0 {
+		capacity = math.Round(weighted / total)
+	}
+
+	data := map[string]any{"dimensions": dimensions}
+	if s.capacityWeights != nil {
+		weights := make(map[string]float64, len(capacityDimensions))
+		for _, d := range capacityDimensions {
+			weights[d.name] = s.capacityWeight(d.name)
+		}
+		data["weights"] = weights
+	}
+	if len(unavailable) > 0 {
+		data["unavailable"] = unavailable
+	}
+	code := http.StatusOK
+	if capacity < 0 {
+		code = http.StatusServiceUnavailable
+	} else {
+		data["capacity"] = capacity
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,102 @@
+package simplehealth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+)
+
+const defaultChildTimeout = 5 * time.Second
+
+// Child is the health endpoint of a sidecar or child process, probed on every
+// run and nested in the response under "children". When Socket is set the
+// request is sent over that unix socket and the host in URL is ignored, e.g.
+// Child{Name: "php", Socket: "/run/php/health.sock", URL: "http://php/health"}.
+type Child struct {
+	Name    string
+	URL     string
+	Socket  string
+	Timeout time.Duration
+}
+
+type childProbe struct {
+	Child
+	client *http.Client
+}
+
+func WithChildren(children ...Child) Option {
+	return func(s *SimpleHealth) {
+		for _, c := range children {
+			timeout := c.Timeout
+			if timeout == 0 {
+				timeout = defaultChildTimeout
+			}
+			client := &http.Client{Timeout: timeout}
+			if c.Socket != "" {
+				socket := c.Socket
+				client.Transport = &http.Transport{
+					DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+						var d net.Dialer
+						return d.DialContext(ctx, "unix", socket)
+					},
+				}
+			}
+			s.children = append(s.children, &childProbe{Child: c, client: client})
+		}
+	}
+}
+
+// probe returns the decoded child response and, when the child is unhealthy,
+// its errors prefixed with the child name.
+func (c *childProbe) probe(ctx context.Context) (any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.Name, err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.Name, err)
+	}
+	defer resp.Body.Close()
+
+	var body map[string]any
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%s: invalid response (HTTP %d): %w", c.Name, resp.StatusCode, err)
+	}
+	if resp.StatusCode < 300 {
+		return body, nil
+	}
+
+	var errs []error
+	if list, ok := body["errors"].([]any); ok {
+		for _, e := range list {
+			errs = append(errs, fmt.Errorf("%s: %v", c.Name, e))
+		}
+	}
+	if len(errs) == 0 {
+		errs = append(errs, fmt.Errorf("%s: unhealthy (HTTP %d)", c.Name, resp.StatusCode))
+	}
+	return body, errors.Join(errs...)
+}
+
+func (s *SimpleHealth) addChildren(data map[string]any, results []CheckResult) {
+	if len(s.children) == 0 {
+		return
+	}
+	children := make(map[string]any, len(s.children))
+	for _, c := range s.children {
+		i := slices.IndexFunc(results, func(r CheckResult) bool { return r.Name == c.Name && r.componentType == "component" })
+		if i >= 0 {
+			children[c.Name] = results[i].Value
+		}
+	}
+	if len(children) > 0 {
+		data["children"] = children
+	}
+}
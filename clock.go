@@ -0,0 +1,28 @@
+package simplehealth
+
+import "time"
+
+// Clock is the time source of age based checks, the history and the runner.
+// Tests can pass a fake one to control time.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func WithClock(c Clock) Option {
+	return func(s *SimpleHealth) {
+		s.clock = c
+	}
+}
+
+func orSystemClock(c Clock) Clock {
+	if c == nil {
+		return systemClock{}
+	}
+	return c
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gwillem/simplehealth"
+	"gopkg.in/yaml.v3"
+)
+
+// expectations is the format of the file given to assert, e.g.
+//
+//	strict: true
+//	checks:
+//	  CheckDisk: {status: pass, max: 0.8}
+//	  CheckLoad: {}
+//	absent: [CheckMDRaid]
+type expectations struct {
+	// Strict also reports live checks that are not listed under checks.
+	Strict bool                   `yaml:"strict"`
+	Checks map[string]expectation `yaml:"checks"`
+	Absent []string               `yaml:"absent"`
+}
+
+type expectation struct {
+	Status string   `yaml:"status"`
+	Min    *float64 `yaml:"min"`
+	Max    *float64 `yaml:"max"`
+}
+
+type liveCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Value  any    `json:"value"`
+}
+
+// assert compares the local checks, or the endpoint given with -url, against
+// an expectation file and exits 1 when they differ.
+func assert(args []string) int {
+	flags := flag.NewFlagSet("assert", flag.ExitOnError)
+	url := flags.String("url", "", "health endpoint to compare instead of running the local checks")
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout for -url")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: simplehealth assert [-url URL] expectations.yaml")
+		return 2
+	}
+
+	exp, err := readExpectations(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var checks []liveCheck
+	if *url != "" {
+		checks, err = fetchChecks(&http.Client{Timeout: *timeout}, *url)
+	} else {
+		checks, err = localChecks()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	diffs := compare(exp, checks)
+	for _, d := range diffs {
+		fmt.Println(d)
+	}
+	if len(diffs) > 0 {
+		return 1
+	}
+	fmt.Printf("%d checks match %s\n", len(checks), flags.Arg(0))
+	return 0
+}
+
+func readExpectations(name string) (expectations, error) {
+	var exp expectations
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return exp, err
+	}
+	if err := yaml.Unmarshal(data, &exp); err != nil {
+		return exp, fmt.Errorf("%s: %w", name, err)
+	}
+	return exp, nil
+}
+
+func localChecks() ([]liveCheck, error) {
+	// round trip through JSON so values compare the same as with -url
+	data, err := json.Marshal(simplehealth.NewSimpleHealth().RunReport().Checks)
+	if err != nil {
+		return nil, err
+	}
+	var checks []liveCheck
+	err = json.Unmarshal(data, &checks)
+	return checks, err
+}
+
+func fetchChecks(client *http.Client, url string) ([]liveCheck, error) {
+	sep := "?"
+	if strings.Contains(url, "?") {
+		sep = "&"
+	}
+	req, err := http.NewRequest(http.MethodGet, url+sep+"verbose=1", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Checks []liveCheck `json:"checks"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", url, resp.Status, err)
+	}
+	return body.Checks, nil
+}
+
+// compare returns the differences between the expectations and the live
+// checks, sorted by check name.
+func compare(exp expectations, checks []liveCheck) []string {
+	live := make(map[string]liveCheck, len(checks))
+	for _, c := range checks {
+		live[c.Name] = c
+	}
+
+	var diffs []string
+	for name, e := range exp.Checks {
+		c, ok := live[name]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("%s: missing", name))
+			continue
+		}
+		if e.Status != "" && c.Status != e.Status {
+			diffs = append(diffs, fmt.Sprintf("%s: status %s, want %s", name, c.Status, e.Status))
+		}
+		if e.Min == nil && e.Max == nil {
+			continue
+		}
+		v, ok := c.Value.(float64)
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("%s: no numeric value", name))
+		case e.Min != nil && v < *e.Min:
+			diffs = append(diffs, fmt.Sprintf("%s: value %g below minimum %g", name, v, *e.Min))
+		case e.Max != nil && v > *e.Max:
+			diffs = append(diffs, fmt.Sprintf("%s: value %g above maximum %g", name, v, *e.Max))
+		}
+	}
+	for _, name := range exp.Absent {
+		if _, ok := live[name]; ok {
+			diffs = append(diffs, fmt.Sprintf("%s: present, want absent", name))
+		}
+	}
+	if exp.Strict {
+		for name := range live {
+			if _, ok := exp.Checks[name]; !ok && !slices.Contains(exp.Absent, name) {
+				diffs = append(diffs, fmt.Sprintf("%s: unexpected", name))
+			}
+		}
+	}
+	sort.Strings(diffs)
+	return diffs
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+type hostResult struct {
+	Host       string   `json:"host"`
+	Healthy    bool     `json:"healthy"`
+	Code       int      `json:"code,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+	DurationMS int64    `json:"duration_ms"`
+}
+
+// fleet queries the endpoints in the hosts file concurrently and exits 1 when
+// any of them is unhealthy or unreachable.
+func fleet(args []string) int {
+	flags := flag.NewFlagSet("fleet", flag.ExitOnError)
+	hostsFile := flags.String("hosts", "", "file with one host or URL per line")
+	path := flags.String("path", "/", "path of the endpoint for lines without a URL")
+	timeout := flags.Duration("timeout", 5*time.Second, "timeout per host")
+	parallel := flags.Int("parallel", 32, "number of hosts to query at the same time")
+	asJSON := flags.Bool("json", false, "print results as JSON")
+	_ = flags.Parse(args)
+	if *hostsFile == "" {
+		flags.Usage()
+		return 2
+	}
+
+	hosts, err := readHosts(*hostsFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	results := make([]hostResult, len(hosts))
+	sem := make(chan struct{}, max(1, *parallel))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			results[i] = query(client, host, endpoint(host, *path))
+			<-sem
+		}()
+	}
+	wg.Wait()
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(results)
+	} else {
+		printTable(os.Stdout, results, useColor())
+	}
+
+	for _, r := range results {
+		if !r.Healthy {
+			return 1
+		}
+	}
+	return 0
+}
+
+func readHosts(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var hosts []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hosts = append(hosts, line)
+	}
+	return hosts, scanner.Err()
+}
+
+func endpoint(host, path string) string {
+	if strings.Contains(host, "://") {
+		return host
+	}
+	return "http://" + host + "/" + strings.TrimPrefix(path, "/")
+}
+
+func query(client *http.Client, host, url string) (r hostResult) {
+	r.Host = host
+	start := time.Now()
+	defer func() { r.DurationMS = time.Since(start).Milliseconds() }()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		r.Errors = []string{err.Error()}
+		return r
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		r.Errors = []string{err.Error()}
+		return r
+	}
+	defer resp.Body.Close()
+
+	r.Code = resp.StatusCode
+	r.Healthy = resp.StatusCode < 300
+	var body struct {
+		Errors []string `json:"errors"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body)
+	r.Errors = body.Errors
+	if !r.Healthy && len(r.Errors) == 0 {
+		r.Errors = []string{resp.Status}
+	}
+	return r
+}
+
+func useColor() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+func printTable(w io.Writer, results []hostResult, color bool) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tSTATUS\tTIME\tERRORS")
+	healthy := 0
+	for _, r := range results {
+		status := "OK"
+		if r.Healthy {
+			healthy++
+		} else {
+			status = "FAIL"
+		}
+		if color {
+			// pad before coloring, tabwriter counts escape codes as width
+			code := "32"
+			if !r.Healthy {
+				code = "31"
+			}
+			status = "\x1b[" + code + "m" + fmt.Sprintf("%-4s", status) + "\x1b[0m"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%dms\t%s\n", r.Host, status, r.DurationMS, strings.Join(r.Errors, "; "))
+	}
+	_ = tw.Flush()
+	fmt.Fprintf(w, "\n%d of %d hosts healthy\n", healthy, len(results))
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const serviceName = "simplehealth"
+
+// service is what install registers with the service manager of the OS:
+// this binary running serve with the given flags.
+type service struct {
+	exe  string
+	args []string
+}
+
+// install registers serve as a systemd unit, launchd agent or Windows service
+// and starts it.
+func install(args []string) int {
+	flags := flag.NewFlagSet("install", flag.ExitOnError)
+	addr := flags.String("addr", defaultAddr, "listen address of the service")
+	interval := flags.Duration("interval", defaultInterval, "time between runs")
+	_ = flags.Parse(args)
+
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	svc := service{exe: exe, args: []string{"serve", "-addr", *addr, "-interval", interval.String()}}
+	where, err := installService(svc)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("installed and started %s\n", where)
+	return 0
+}
+
+// uninstall stops and removes what install registered.
+func uninstall(args []string) int {
+	flags := flag.NewFlagSet("uninstall", flag.ExitOnError)
+	_ = flags.Parse(args)
+	where, err := uninstallService()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("removed %s\n", where)
+	return 0
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const launchdLabel = "com.github.gwillem." + serviceName
+
+const plistTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+%s	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardErrorPath</key>
+	<string>%s</string>
+</dict>
+</plist>
+`
+
+// agentPaths returns the plist of the launchd agent of the current user and
+// its log file.
+func agentPaths() (plist, log string, err error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"),
+		filepath.Join(home, "Library", "Logs", serviceName+".log"), nil
+}
+
+func installService(svc service) (string, error) {
+	plist, log, err := agentPaths()
+	if err != nil {
+		return "", err
+	}
+	var args strings.Builder
+	for _, a := range append([]string{svc.exe}, svc.args...) {
+		args.WriteString("\t\t<string>")
+		if err := xml.EscapeText(&args, []byte(a)); err != nil {
+			return "", err
+		}
+		args.WriteString("</string>\n")
+	}
+	var escapedLog bytes.Buffer
+	if err := xml.EscapeText(&escapedLog, []byte(log)); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(plist), 0o755); err != nil {
+		return "", err
+	}
+	data := fmt.Sprintf(plistTemplate, launchdLabel, args.String(), escapedLog.String())
+	if err := os.WriteFile(plist, []byte(data), 0o644); err != nil {
+		return "", err
+	}
+	return "launchd agent " + plist, launchctl("load", "-w", plist)
+}
+
+func uninstallService() (string, error) {
+	plist, _, err := agentPaths()
+	if err != nil {
+		return "", err
+	}
+	if err := launchctl("unload", "-w", plist); err != nil {
+		return "", err
+	}
+	return "launchd agent " + plist, os.Remove(plist)
+}
+
+func launchctl(args ...string) error {
+	out, err := exec.Command("launchctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("launchctl %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var unitPath = "/etc/systemd/system/" + serviceName + ".service"
+
+// Type=notify, serve reports READY=1 once listening. The dynamic user gets
+// CAP_SYS_PTRACE to count the open files of other processes in
+// /proc/<pid>/fd and CAP_DAC_READ_SEARCH to read the files and directories
+// the checks inspect, and no other privileges.
+const unitTemplate = `[Unit]
+Description=simplehealth health checks
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=notify
+ExecStart=%s
+Restart=on-failure
+RestartSec=5s
+DynamicUser=yes
+AmbientCapabilities=CAP_SYS_PTRACE CAP_DAC_READ_SEARCH
+CapabilityBoundingSet=CAP_SYS_PTRACE CAP_DAC_READ_SEARCH
+ProtectSystem=strict
+ProtectHome=read-only
+NoNewPrivileges=yes
+
+[Install]
+WantedBy=multi-user.target
+`
+
+func installService(svc service) (string, error) {
+	words := make([]string, 0, 1+len(svc.args))
+	for _, w := range append([]string{svc.exe}, svc.args...) {
+		words = append(words, systemdQuote(w))
+	}
+	unit := fmt.Sprintf(unitTemplate, strings.Join(words, " "))
+	if err := os.WriteFile(unitPath, []byte(unit), 0o644); err != nil {
+		return "", err
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return "", err
+	}
+	return "systemd unit " + unitPath, systemctl("enable", "--now", serviceName)
+}
+
+func uninstallService() (string, error) {
+	if err := systemctl("disable", "--now", serviceName); err != nil {
+		return "", err
+	}
+	if err := os.Remove(unitPath); err != nil {
+		return "", err
+	}
+	return "systemd unit " + unitPath, systemctl("daemon-reload")
+}
+
+// systemdQuote quotes w for a command line in a unit file, where % starts a
+// specifier and $ a variable.
+func systemdQuote(w string) string {
+	w = strings.NewReplacer("%", "%%", "$", "$$").Replace(w)
+	if w != "" && !strings.ContainsAny(w, " \t\n\"'\\;") {
+		return w
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(w) + `"`
+}
+
+func systemctl(args ...string) error {
+	out, err := exec.Command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
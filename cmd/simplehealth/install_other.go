@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !windows
+
+package main
+
+import (
+	"errors"
+	"runtime"
+)
+
+var errNoServiceManager = errors.New("install is not supported on " + runtime.GOOS)
+
+func installService(service) (string, error) { return "", errNoServiceManager }
+
+func uninstallService() (string, error) { return "", errNoServiceManager }
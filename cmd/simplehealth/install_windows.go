@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+func installService(s service) (string, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return "", err
+	}
+	defer m.Disconnect()
+
+	ws, err := m.CreateService(serviceName, s.exe, mgr.Config{
+		DisplayName: "simplehealth",
+		Description: "Serves the simplehealth checks of this host.",
+		StartType:   mgr.StartAutomatic,
+	}, s.args...)
+	if err != nil {
+		return "", err
+	}
+	defer ws.Close()
+	return "Windows service " + serviceName, ws.Start()
+}
+
+func uninstallService() (string, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return "", err
+	}
+	defer m.Disconnect()
+
+	ws, err := m.OpenService(serviceName)
+	if err != nil {
+		return "", err
+	}
+	defer ws.Close()
+	if status, err := ws.Control(svc.Stop); err == nil {
+		for deadline := time.Now().Add(20 * time.Second); status.State != svc.Stopped && time.Now().Before(deadline); {
+			time.Sleep(300 * time.Millisecond)
+			if status, err = ws.Query(); err != nil {
+				break
+			}
+		}
+	}
+	return "Windows service " + serviceName, ws.Delete()
+}
+
+// runService runs serve under the service control manager when started by
+// it, and reports false otherwise.
+func runService(run func(ctx context.Context) error) (bool, error) {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return false, err
+	}
+	h := &serviceHandler{run: run}
+	if err := svc.Run(serviceName, h); err != nil {
+		return true, err
+	}
+	return true, h.err
+}
+
+type serviceHandler struct {
+	run func(ctx context.Context) error
+	err error
+}
+
+func (h *serviceHandler) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- h.run(ctx) }()
+
+	const accepts = svc.AcceptStop | svc.AcceptShutdown
+	status <- svc.Status{State: svc.Running, Accepts: accepts}
+	for {
+		select {
+		case err := <-done:
+			h.err = err
+			if err != nil {
+				return true, 1
+			}
+			return false, 0
+		case r := <-requests:
+			switch r.Cmd {
+			case svc.Interrogate:
+				status <- r.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				cancel()
+			}
+		}
+	}
+}
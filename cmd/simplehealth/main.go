@@ -0,0 +1,50 @@
+// Command simplehealth queries simplehealth endpoints from the shell.
+//
+//	simplehealth fleet -hosts hosts.txt
+//	simplehealth wait -stable 2m -timeout 10m
+//	simplehealth assert expectations.yaml
+//	simplehealth serve -addr :8080
+//	simplehealth install -addr :8080
+//	simplehealth self-update
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+var commands = map[string]func(args []string) int{
+	"assert":      assert,
+	"fleet":       fleet,
+	"install":     install,
+	"self-update": selfUpdate,
+	"serve":       serve,
+	"uninstall":   uninstall,
+	"wait":        wait,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+	}
+	os.Exit(cmd(os.Args[2:]))
+}
+
+func usage() {
+	fmt.Fprint(os.Stderr, `usage: simplehealth <command> [flags]
+
+commands:
+  fleet        query the health endpoints of many hosts
+  wait         block until the checks pass for a stabilization window
+  assert       compare the checks against an expectation file
+  serve        run the checks periodically and serve them over HTTP
+  install      run serve as a systemd unit, launchd agent or Windows service
+  uninstall    remove the service of install
+  self-update  replace this binary with the latest signed release
+`)
+	os.Exit(2)
+}
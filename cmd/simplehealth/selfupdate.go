@@ -0,0 +1,277 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Set at release time, e.g.
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.releaseURL=https://example.com/simplehealth -X main.releaseKey=..."
+var (
+	version    = ""
+	releaseURL = ""
+	releaseKey = "" // base64 ed25519 public key
+)
+
+const maxBinarySize = 256 << 20
+
+// selfUpdate replaces the running binary with the latest release. A release
+// directory holds the version in a file named latest and per version the
+// binaries with a sha256sum manifest of them, run from the release directory,
+// and its ed25519 signature:
+//
+//	latest
+//	v1.2.0/SHA256SUMS      <hex digest>  v1.2.0/simplehealth-linux-amd64
+//	v1.2.0/SHA256SUMS.sig
+//	v1.2.0/simplehealth-linux-amd64
+//
+// The manifest binds the digest to the version and platform, so a signed
+// binary cannot be served as another one. Releases older than the running
+// version are refused unless -force.
+func selfUpdate(args []string) int {
+	flags := flag.NewFlagSet("self-update", flag.ExitOnError)
+	url := flags.String("url", releaseURL, "release directory")
+	key := flags.String("key", releaseKey, "base64 ed25519 public key the releases are signed with")
+	timeout := flags.Duration("timeout", 5*time.Minute, "give up after this long")
+	force := flags.Bool("force", false, "install the latest release even if it is the running or an older version")
+	_ = flags.Parse(args)
+	if *url == "" || *key == "" {
+		fmt.Fprintln(os.Stderr, "usage: simplehealth self-update -url URL -key KEY")
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	installed, err := update(ctx, strings.TrimSuffix(*url, "/"), *key, *force)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "self-update:", err)
+		return 1
+	}
+	if installed == "" {
+		fmt.Printf("%s is the latest release\n", currentVersion())
+		return 0
+	}
+	fmt.Printf("updated from %s to %s\n", currentVersion(), installed)
+	return 0
+}
+
+func currentVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// update installs the latest release and returns its version, or "" when
+// it is already running.
+func update(ctx context.Context, url, key string, force bool) (string, error) {
+	pub, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return "", errors.New("invalid public key")
+	}
+
+	latest, err := fetch(ctx, url+"/latest", 128)
+	if err != nil {
+		return "", err
+	}
+	v := strings.TrimSpace(string(latest))
+	if v == "" || strings.ContainsAny(v, "/\\") {
+		return "", fmt.Errorf("invalid version %q", v)
+	}
+	if !force {
+		c, ok := compareVersions(v, currentVersion())
+		switch {
+		case !ok:
+			return "", fmt.Errorf("cannot compare release %s with %s, use -force to install it", v, currentVersion())
+		case c == 0:
+			return "", nil
+		case c < 0:
+			return "", fmt.Errorf("latest release %s is older than %s, use -force to downgrade", v, currentVersion())
+		}
+	}
+
+	name := fmt.Sprintf("simplehealth-%s-%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	manifest, err := fetch(ctx, url+"/"+v+"/SHA256SUMS", 64<<10)
+	if err != nil {
+		return "", err
+	}
+	sig, err := fetch(ctx, url+"/"+v+"/SHA256SUMS.sig", ed25519.SignatureSize)
+	if err != nil {
+		return "", err
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pub), manifest, sig) {
+		return "", fmt.Errorf("%s SHA256SUMS: invalid signature", v)
+	}
+	want, err := manifestDigest(manifest, v+"/"+name)
+	if err != nil {
+		return "", err
+	}
+
+	binary, err := fetch(ctx, url+"/"+v+"/"+name, maxBinarySize)
+	if err != nil {
+		return "", err
+	}
+	if got := sha256.Sum256(binary); hex.EncodeToString(got[:]) != want {
+		return "", fmt.Errorf("%s/%s: sha256 does not match SHA256SUMS", v, name)
+	}
+	return v, replaceExecutable(binary)
+}
+
+// manifestDigest returns the lower case hex sha256 of file in a sha256sum
+// manifest.
+func manifestDigest(manifest []byte, file string) (string, error) {
+	for _, line := range strings.Split(string(manifest), "\n") {
+		digest, name, ok := strings.Cut(strings.TrimSpace(line), " ")
+		// binary mode entries are prefixed with '*'
+		if ok && strings.TrimPrefix(strings.TrimLeft(name, " "), "*") == file {
+			return strings.ToLower(digest), nil
+		}
+	}
+	return "", fmt.Errorf("%s is not in SHA256SUMS", file)
+}
+
+// compareVersions compares vMAJOR.MINOR.PATCH[-PRE] versions like semver and
+// returns 1 when a is newer, -1 when b is, or 0 when they are equal. ok is
+// false when either cannot be parsed, e.g. for a "(devel)" build.
+func compareVersions(a, b string) (c int, ok bool) {
+	pa, prea, okA := parseVersion(a)
+	pb, preb, okB := parseVersion(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			return cmp.Compare(pa[i], pb[i]), true
+		}
+	}
+	switch {
+	case prea == preb:
+		return 0, true
+	case prea == "":
+		return 1, true
+	case preb == "":
+		return -1, true
+	}
+	return cmp.Compare(prea, preb), true
+}
+
+func parseVersion(v string) (parts [3]int, pre string, ok bool) {
+	v, ok = strings.CutPrefix(v, "v")
+	if !ok {
+		return parts, "", false
+	}
+	v, _, _ = strings.Cut(v, "+")
+	v, pre, _ = strings.Cut(v, "-")
+	fields := strings.Split(v, ".")
+	if len(fields) != 3 {
+		return parts, "", false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, "", false
+		}
+		parts[i] = n
+	}
+	return parts, pre, true
+}
+
+func fetch(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("GET %s: larger than %d bytes", url, limit)
+	}
+	return data, nil
+}
+
+// replaceExecutable writes binary next to the running executable and renames
+// it over, so the old binary stays intact until the new one is complete.
+// Windows cannot replace a running executable, it is moved aside first.
+func replaceExecutable(binary []byte) error {
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".simplehealth-update-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0o111); err != nil {
+		return err
+	}
+
+	if runtime.GOOS != "windows" {
+		return os.Rename(tmp.Name(), exe)
+	}
+	old := exe + ".old"
+	_ = os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		// put the running binary back rather than leave no executable
+		if rerr := os.Rename(old, exe); rerr != nil {
+			return fmt.Errorf("%w; restoring %s: %v", err, old, rerr)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		want   int
+		wantOK bool
+	}{
+		{"v1.2.3", "v1.2.3", 0, true},
+		{"v1.2.4", "v1.2.3", 1, true},
+		{"v1.2.3", "v1.10.0", -1, true},
+		{"v2.0.0", "v1.99.99", 1, true},
+		{"v1.0.0", "v1.0.0-rc.1", 1, true},
+		{"v1.0.0-rc.1", "v1.0.0", -1, true},
+		{"v1.0.0-rc.2", "v1.0.0-rc.1", 1, true},
+		{"v1.0.0+build.5", "v1.0.0", 0, true},
+		{"v1.0.0", "(devel)", 0, false},
+		{"(devel)", "(devel)", 0, false},
+		{"1.0.0", "v1.0.0", 0, false},
+		{"v1.0", "v1.0.0", 0, false},
+		{"v1.x.0", "v1.0.0", 0, false},
+		{"v1.-1.0", "v1.0.0", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			got, ok := compareVersions(tt.a, tt.b)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("compareVersions(%q, %q) = %d, %v, want %d, %v", tt.a, tt.b, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestManifestDigest(t *testing.T) {
+	manifest := []byte("ABCDEF  simplehealth-linux-amd64\n" +
+		"123456 *simplehealth-windows-amd64.exe\n" +
+		"\n" +
+		"  fedcba  simplehealth-darwin-arm64  \n")
+	tests := []struct {
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{"simplehealth-linux-amd64", "abcdef", false},
+		{"simplehealth-windows-amd64.exe", "123456", false},
+		{"simplehealth-darwin-arm64", "fedcba", false},
+		{"simplehealth-linux-arm64", "", true},
+		{"simplehealth-linux", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			got, err := manifestDigest(manifest, tt.file)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("manifestDigest(%q) = %q, %v, want %q, error %v", tt.file, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gwillem/simplehealth"
+)
+
+const (
+	defaultAddr     = ":8080"
+	defaultInterval = 30 * time.Second
+)
+
+// serve runs the default checks every interval and serves them on addr until
+// interrupted, or stopped by the service manager.
+func serve(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", defaultAddr, `listen address, or "unix:/path" for a unix socket`)
+	interval := flags.Duration("interval", defaultInterval, "time between runs")
+	_ = flags.Parse(args)
+
+	run := func(ctx context.Context) error {
+		logger := slog.Default()
+		health := simplehealth.NewSimpleHealth(
+			simplehealth.WithLogger(logger),
+			simplehealth.WithCachedResults(3**interval),
+		)
+		health.Start(ctx, *interval)
+		logger.Info("serving health checks", "addr", *addr, "interval", *interval)
+		return health.ListenAndServe(ctx, *addr)
+	}
+
+	if ok, err := runService(run); ok {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := run(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
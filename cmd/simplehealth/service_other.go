@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import "context"
+
+// runService reports false, only Windows starts serve through a service
+// manager API, systemd and launchd just run it.
+func runService(func(ctx context.Context) error) (bool, error) {
+	return false, nil
+}
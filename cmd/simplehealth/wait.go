@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gwillem/simplehealth"
+)
+
+// wait blocks until the local checks, or the endpoint given with -url, have
+// been healthy for the stabilization window, and exits 1 on timeout.
+func wait(args []string) int {
+	flags := flag.NewFlagSet("wait", flag.ExitOnError)
+	state := flags.String("for", "healthy", "state to wait for, only healthy is supported")
+	stable := flags.Duration("stable", time.Minute, "how long checks must pass without interruption")
+	timeout := flags.Duration("timeout", 10*time.Minute, "give up after this long")
+	interval := flags.Duration("interval", 5*time.Second, "time between runs")
+	url := flags.String("url", "", "health endpoint to poll instead of running the local checks")
+	_ = flags.Parse(args)
+	if *state != "healthy" {
+		fmt.Fprintf(os.Stderr, "cannot wait for %q, only healthy is supported\n", *state)
+		return 2
+	}
+
+	var health *simplehealth.SimpleHealth
+	if *url != "" {
+		health = simplehealth.NewSimpleHealth(simplehealth.WithChildren(simplehealth.Child{Name: *url, URL: *url}))
+		health.SetChecks()
+	} else {
+		health = simplehealth.NewSimpleHealth()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	start := time.Now()
+	if err := health.WaitHealthy(ctx, *stable, *interval); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("healthy for %s after %s\n", *stable, time.Since(start).Round(time.Second))
+	return 0
+}
@@ -0,0 +1,102 @@
+package simplehealth
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Collector discovers a set of checks on every run, e.g. one per container,
+// keyed by name. A nil error is a passing check. Collectors implementing
+// ContextCollector see the context of the run, which ends at WithRunTimeout.
+type Collector interface {
+	Collect() (map[string]error, error)
+}
+
+// ContextCollector is a Collector that stops when ctx is done.
+type ContextCollector interface {
+	Collector
+	CollectContext(ctx context.Context) (map[string]error, error)
+}
+
+type collector struct {
+	name string
+	c    Collector
+	opts []CheckOption
+}
+
+// AddCollector adds the checks of c as "name/<key>". When c itself fails a
+// single check named name fails. opts apply to every collected check.
+func (s *SimpleHealth) AddCollector(name string, c Collector, opts ...CheckOption) {
+	s.collectors = append(s.collectors, collector{name: name, c: c, opts: opts})
+}
+
+// collect runs the collectors that tags select in parallel and returns
+// their checks. A collector still running when ctx is done fails.
+func (s *SimpleHealth) collect(ctx context.Context, tags []string, others []check) []check {
+	collected := make([][]check, len(s.collectors))
+	done := make([]chan []check, len(s.collectors))
+	for i, col := range s.collectors {
+		if len(tags) > 0 && !col.selected(tags, others) {
+			continue
+		}
+		done[i] = make(chan []check, 1)
+		go func() {
+			done[i] <- col.checks(ctx)
+		}()
+	}
+	for i, ch := range done {
+		if ch == nil {
+			continue
+		}
+		select {
+		case collected[i] = <-ch:
+		case <-ctx.Done():
+			col := s.collectors[i]
+			collected[i] = []check{col.check(col.name, fmt.Errorf("collector did not finish: %w", ctx.Err()))}
+		}
+	}
+	return slices.Concat(collected...)
+}
+
+// selected reports whether a run with tags needs the checks of col.
+func (col collector) selected(tags []string, others []check) bool {
+	if c := col.check(col.name, nil); slices.ContainsFunc(c.tags, func(t string) bool { return slices.Contains(tags, t) }) {
+		return true
+	}
+	return slices.ContainsFunc(others, func(c check) bool {
+		return slices.ContainsFunc(c.dependsOn, func(name string) bool {
+			return name == col.name || strings.HasPrefix(name, col.name+"/")
+		})
+	})
+}
+
+func (col collector) checks(ctx context.Context) []check {
+	var (
+		results map[string]error
+		err     error
+	)
+	if cc, ok := col.c.(ContextCollector); ok {
+		results, err = cc.CollectContext(ctx)
+	} else {
+		results, err = col.c.Collect()
+	}
+	if err != nil {
+		return []check{col.check(col.name, err)}
+	}
+	var checks []check
+	for _, key := range slices.Sorted(maps.Keys(results)) {
+		checks = append(checks, col.check(col.name+"/"+key, results[key]))
+	}
+	return checks
+}
+
+func (col collector) check(name string, err error) check {
+	c := check{name: name, fn: func() (any, error) { return nil, err }}
+	for _, opt := range col.opts {
+		opt(&c)
+	}
+	return c
+}
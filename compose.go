@@ -0,0 +1,102 @@
+package simplehealth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+var dockerPath = "docker"
+
+// ComposeCollector reports the containers of a Docker Compose project per
+// service, failing those that are unhealthy or stopped with a non-zero exit
+// code. Project selects the project by name, Dir by its working directory;
+// both empty uses the compose project of the current directory.
+type ComposeCollector struct {
+	Project string
+	Dir     string
+}
+
+type composeContainer struct {
+	Name     string
+	Service  string
+	State    string
+	Health   string
+	ExitCode int
+}
+
+func (c ComposeCollector) Collect() (map[string]error, error) {
+	return c.CollectContext(context.Background())
+}
+
+func (c ComposeCollector) CollectContext(ctx context.Context) (map[string]error, error) {
+	args := []string{"compose"}
+	if c.Project != "" {
+		args = append(args, "--project-name", c.Project)
+	}
+	args = append(args, "ps", "--all", "--format", "json")
+	cmd := exec.CommandContext(ctx, dockerPath, args...)
+	cmd.Dir = c.Dir
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("docker compose ps: %s", bytes.TrimSpace(exitErr.Stderr))
+		}
+		return nil, unavailable("docker compose", err)
+	}
+
+	containers, err := parseComposePS(out)
+	if err != nil {
+		return nil, err
+	}
+	services := make(map[string][]error)
+	for _, ct := range containers {
+		var err error
+		switch {
+		case ct.Health == "unhealthy":
+			err = fmt.Errorf("container %s is unhealthy", ct.Name)
+		case ct.State == "exited" && ct.ExitCode != 0:
+			err = fmt.Errorf("container %s exited with code %d", ct.Name, ct.ExitCode)
+		case ct.State != "running" && ct.State != "exited":
+			err = fmt.Errorf("container %s is %s", ct.Name, ct.State)
+		}
+		services[ct.Service] = append(services[ct.Service], err)
+	}
+
+	results := make(map[string]error, len(services))
+	for service, errs := range services {
+		results[service] = errors.Join(errs...)
+	}
+	return results, nil
+}
+
+// parseComposePS reads both the JSON array of compose v2 before 2.21 and the
+// JSON line per container of later versions.
+func parseComposePS(out []byte) ([]composeContainer, error) {
+	out = bytes.TrimSpace(out)
+	var containers []composeContainer
+	if bytes.HasPrefix(out, []byte("[")) {
+		err := json.Unmarshal(out, &containers)
+		return containers, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var ct composeContainer
+		if err := json.Unmarshal([]byte(line), &ct); err != nil {
+			return nil, fmt.Errorf("docker compose ps: %w", err)
+		}
+		containers = append(containers, ct)
+	}
+	return containers, scanner.Err()
+}
@@ -0,0 +1,302 @@
+package simplehealth
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var containerSockets = []struct{ runtime, socket string }{
+	{"docker", "/var/run/docker.sock"},
+	{"podman", "/run/podman/podman.sock"},
+	{"podman", "$XDG_RUNTIME_DIR/podman/podman.sock"},
+	{"containerd", "/run/containerd/containerd.sock"},
+}
+
+// ContainerCollector reports every container of a runtime by name, failing
+// those that are unhealthy, restarting or exited with a non-zero code.
+// Docker and Podman are queried over their Docker compatible REST socket,
+// containerd over its gRPC socket, which has no notion of health checks and
+// only reports tasks that are not running. Without Runtime the first socket
+// found of docker, podman (rootful, then rootless) and containerd is used. A
+// Socket without Runtime must be named after its runtime, like
+// /run/k3s/containerd/containerd.sock.
+type ContainerCollector struct {
+	Runtime   string // "docker", "podman" or "containerd"
+	Socket    string
+	Namespace string // containerd namespace, defaults to "default"
+}
+
+func (c ContainerCollector) Collect() (map[string]error, error) {
+	return c.CollectContext(context.Background())
+}
+
+func (c ContainerCollector) CollectContext(ctx context.Context) (map[string]error, error) {
+	runtime, socket, err := c.detect()
+	if err != nil {
+		return nil, err
+	}
+	if runtime == "containerd" {
+		return c.collectContainerd(ctx, socket)
+	}
+	return collectREST(ctx, socket)
+}
+
+func (c ContainerCollector) detect() (runtime, socket string, err error) {
+	if c.Socket != "" {
+		if c.Runtime != "" {
+			return c.Runtime, c.Socket, nil
+		}
+		for _, s := range containerSockets {
+			if filepath.Base(c.Socket) == filepath.Base(s.socket) {
+				return s.runtime, c.Socket, nil
+			}
+		}
+		return "", "", fmt.Errorf("cannot tell the container runtime of socket %s, set Runtime", c.Socket)
+	}
+	for _, s := range containerSockets {
+		if c.Runtime != "" && c.Runtime != s.runtime {
+			continue
+		}
+		socket := os.ExpandEnv(s.socket)
+		if !filepath.IsAbs(socket) {
+			continue
+		}
+		if _, err := os.Stat(socket); err == nil {
+			return s.runtime, socket, nil
+		}
+	}
+	if c.Runtime == "" {
+		return "", "", unavailable("container runtime", errors.New("no docker, podman or containerd socket found"))
+	}
+	return "", "", fmt.Errorf("no socket found for container runtime %q", c.Runtime)
+}
+
+type restContainer struct {
+	Names  []string
+	State  string
+	Status string
+}
+
+func collectREST(ctx context.Context, socket string) (map[string]error, error) {
+	client := &http.Client{
+		Timeout: defaultChildTimeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/containers/json?all=1", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, unavailable("container runtime", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("list containers on %s: %s", socket, resp.Status)
+	}
+
+	var containers []restContainer
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, err
+	}
+	results := make(map[string]error, len(containers))
+	for _, ct := range containers {
+		if len(ct.Names) == 0 {
+			continue
+		}
+		name := strings.TrimPrefix(ct.Names[0], "/")
+		results[name] = restContainerError(name, ct)
+	}
+	return results, nil
+}
+
+func restContainerError(name string, ct restContainer) error {
+	switch ct.State {
+	case "running":
+		// e.g. "Up 3 hours (unhealthy)"
+		if strings.Contains(ct.Status, "(unhealthy)") {
+			return fmt.Errorf("container %s is unhealthy", name)
+		}
+		return nil
+	case "exited":
+		// e.g. "Exited (137) 5 minutes ago"
+		var code int
+		if _, err := fmt.Sscanf(ct.Status, "Exited (%d)", &code); err == nil && code == 0 {
+			return nil
+		}
+		return fmt.Errorf("container %s %s", name, strings.ToLower(ct.Status))
+	case "created":
+		return nil
+	default:
+		return fmt.Errorf("container %s is %s", name, ct.State)
+	}
+}
+
+func (c ContainerCollector) collectContainerd(ctx context.Context, socket string) (map[string]error, error) {
+	ns := c.Namespace
+	if ns == "" {
+		ns = "default"
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaultChildTimeout)
+	defer cancel()
+	// an empty ListTasksRequest, framed as uncompressed gRPC message
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://containerd/containerd.services.tasks.v1.Tasks/List", bytes.NewReader(make([]byte, 5)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	req.Header.Set("containerd-namespace", ns)
+
+	// containerd only speaks gRPC, which is HTTP/2 without TLS
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{
+		Protocols: &protocols,
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, unavailable("containerd", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, unavailable("containerd", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("list tasks on %s: %s", socket, resp.Status)
+	}
+	// the status is a header for errors without a body, else a trailer
+	status := resp.Header.Get("Grpc-Status")
+	msg := resp.Header.Get("Grpc-Message")
+	if status == "" {
+		status, msg = resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	}
+	if status != "0" {
+		return nil, fmt.Errorf("list tasks on %s: grpc status %s: %s", socket, status, msg)
+	}
+	if len(body) < 5 || body[0] != 0 || int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5 {
+		return nil, fmt.Errorf("list tasks on %s: malformed response", socket)
+	}
+
+	tasks, err := parseTasks(body[5:])
+	if err != nil {
+		return nil, fmt.Errorf("list tasks on %s: %w", socket, err)
+	}
+	results := make(map[string]error, len(tasks))
+	for _, t := range tasks {
+		if t.status != taskRunning {
+			results[t.id] = fmt.Errorf("task %s is %s", t.id, taskStatusName(t.status))
+		} else {
+			results[t.id] = nil
+		}
+	}
+	return results, nil
+}
+
+// Task status of containerd.v1.types.Status.
+const taskRunning = 2
+
+func taskStatusName(status uint64) string {
+	names := []string{"unknown", "created", "running", "stopped", "paused", "pausing"}
+	if status < uint64(len(names)) {
+		return names[status]
+	}
+	return fmt.Sprintf("in status %d", status)
+}
+
+type containerdTask struct {
+	id     string
+	status uint64
+}
+
+// parseTasks decodes a containerd ListTasksResponse: field 1 repeats
+// Process, with the task id in field 2 and the status in field 4.
+func parseTasks(b []byte) ([]containerdTask, error) {
+	var tasks []containerdTask
+	err := protoFields(b, func(num int, varint uint64, data []byte) error {
+		if num != 1 || data == nil {
+			return nil
+		}
+		var t containerdTask
+		err := protoFields(data, func(num int, varint uint64, data []byte) error {
+			switch {
+			case num == 2 && data != nil:
+				t.id = string(data)
+			case num == 4 && data == nil:
+				t.status = varint
+			}
+			return nil
+		})
+		tasks = append(tasks, t)
+		return err
+	})
+	return tasks, err
+}
+
+var errMalformedProto = errors.New("malformed protobuf message")
+
+// protoFields calls fn for every field of a protobuf message, with data set
+// for length delimited fields and varint for the others.
+func protoFields(b []byte, fn func(num int, varint uint64, data []byte) error) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errMalformedProto
+		}
+		b = b[n:]
+		var varint uint64
+		var data []byte
+		switch key & 7 {
+		case 0: // varint
+			if varint, n = binary.Uvarint(b); n <= 0 {
+				return errMalformedProto
+			}
+			b = b[n:]
+		case 1: // fixed64
+			if len(b) < 8 {
+				return errMalformedProto
+			}
+			b = b[8:]
+		case 2: // length delimited
+			size, n := binary.Uvarint(b)
+			if n <= 0 || size > uint64(len(b)-n) {
+				return errMalformedProto
+			}
+			data, b = b[n:n+int(size)], b[n+int(size):]
+			if data == nil {
+				data = []byte{}
+			}
+		case 5: // fixed32
+			if len(b) < 4 {
+				return errMalformedProto
+			}
+			b = b[4:]
+		default:
+			return errMalformedProto
+		}
+		if err := fn(int(key>>3), varint, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package simplehealth
+
+import "context"
+
+// ContextChecker is a Checker that also accepts the context of the run. Add
+// and AddChecks prefer CheckContext when a Checker implements it.
+type ContextChecker interface {
+	Checker
+	CheckContext(ctx context.Context) error
+}
+
+// AddContextCheck adds a check that receives the context of the run. For
+// runs of Handler and the other handlers it carries the values of the
+// request context, such as the trace of the span the check runs in, but not
+// its cancellation, so a client that hangs up cannot fail the run. Runs
+// without a request, like those of Start, pass a background context.
+// WithRunTimeout sets the deadline of both.
+func (s *SimpleHealth) AddContextCheck(name string, check func(ctx context.Context) error, opts ...CheckOption) {
+	s.addCheck(name, nil, append([]CheckOption{withContextFunc(func(ctx context.Context) (any, error) {
+		return nil, check(ctx)
+	})}, opts...)...)
+}
+
+func withContextFunc(fn func(context.Context) (any, error)) CheckOption {
+	return func(c *check) {
+		c.fnContext = fn
+	}
+}
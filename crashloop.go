@@ -0,0 +1,84 @@
+package simplehealth
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var systemctlPath = "systemctl"
+
+type restartSample struct {
+	time  time.Time
+	count int64
+}
+
+// CheckCrashLoop fails when one of the systemd units restarted more than
+// maxRestarts times within window, a service that is up right now but keeps
+// crashing. It tracks the NRestarts counter of systemd 235 and later between
+// runs, so the first run only records a baseline and window should span a
+// few runs.
+func CheckCrashLoop(maxRestarts int, window time.Duration, units ...string) ValidatedCheck {
+	var (
+		mu      sync.Mutex
+		samples = make(map[string][]restartSample)
+	)
+
+	return validatedClock(func(clock Clock) error {
+		if err := linuxOnly("systemd restart counters"); err != nil {
+			return err
+		}
+		now := clock.Now()
+		mu.Lock()
+		defer mu.Unlock()
+
+		var errs []error
+		for _, unit := range units {
+			count, err := unitRestarts(unit)
+			if err != nil {
+				return err
+			}
+			s := samples[unit]
+			if len(s) > 0 && count < s[len(s)-1].count {
+				// the unit was reset or reloaded, start over
+				s = nil
+			}
+			s = append(s, restartSample{now, count})
+			// keep the newest sample from before the window as baseline
+			for len(s) > 1 && now.Sub(s[1].time) >= window {
+				s = s[1:]
+			}
+			samples[unit] = s
+
+			if restarts := count - s[0].count; restarts > int64(maxRestarts) {
+				errs = append(errs, withHint(fmt.Errorf("%s restarted %d times in the last %s", unit, restarts, window), fmt.Sprintf(hintCrashLoop, unit)))
+			}
+		}
+		return errors.Join(errs...)
+	}, func() error {
+		switch {
+		case maxRestarts < 0:
+			return fmt.Errorf("maxRestarts must not be negative, got %d", maxRestarts)
+		case len(units) == 0:
+			return errors.New("no units configured")
+		}
+		return validatePositive("window", window)
+	})
+}
+
+func unitRestarts(unit string) (int64, error) {
+	out, err := exec.Command(systemctlPath, "show", "--property=NRestarts", "--value", unit).Output()
+	if err != nil {
+		// no systemctl, or no systemd to talk to, e.g. in a container
+		return 0, unavailable("systemd", fmt.Errorf("systemctl show %s: %w", unit, err))
+	}
+	value := strings.TrimSpace(string(out))
+	if value == "" {
+		return 0, fmt.Errorf("%s: no NRestarts, systemd before 235?", unit)
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
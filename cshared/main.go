@@ -0,0 +1,63 @@
+// Command cshared exposes simplehealth as a C shared library, so non-Go
+// daemons (PHP FFI, Python ctypes, ...) on the same host can embed the same
+// checks:
+//
+//	go build -buildmode=c-shared -o libsimplehealth.so ./cshared
+//
+// From Python:
+//
+//	lib = ctypes.CDLL("./libsimplehealth.so")
+//	lib.SimpleHealthRun.restype = ctypes.c_void_p
+//	lib.SimpleHealthFree.argtypes = [ctypes.c_void_p]
+//	status = ctypes.c_int()
+//	ptr = lib.SimpleHealthRun(ctypes.byref(status))
+//	body = ctypes.string_at(ptr).decode()
+//	lib.SimpleHealthFree(ptr)
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"unsafe"
+
+	"github.com/gwillem/simplehealth"
+)
+
+var health = simplehealth.NewSimpleHealth()
+
+// SimpleHealthRun runs the default checks and returns the JSON response,
+// storing the HTTP status code in status when it is not NULL. The result must
+// be released with SimpleHealthFree.
+//
+//export SimpleHealthRun
+func SimpleHealthRun(status *C.int) *C.char {
+	rec := &recorder{header: http.Header{}, code: http.StatusOK}
+	health.Handler(rec, &http.Request{Method: http.MethodGet, URL: &url.URL{Path: "/"}, Header: http.Header{}})
+	if status != nil {
+		*status = C.int(rec.code)
+	}
+	return C.CString(rec.body.String())
+}
+
+//export SimpleHealthFree
+func SimpleHealthFree(p *C.char) {
+	C.free(unsafe.Pointer(p))
+}
+
+type recorder struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header         { return r.header }
+func (r *recorder) Write(b []byte) (int, error) { return r.body.Write(b) }
+func (r *recorder) WriteHeader(code int)        { r.code = code }
+
+func main() {}
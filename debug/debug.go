@@ -0,0 +1,43 @@
+// Package debug serves pprof and expvar for diagnosing slow checks in
+// production. It lives in its own package because importing net/http/pprof
+// and expvar registers their handlers on http.DefaultServeMux, which users of
+// simplehealth should only opt into explicitly.
+package debug
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+)
+
+// Handler serves the pprof index and profiles under <prefix>/pprof/ and the
+// expvar variables under <prefix>/vars. Profiles expose the command line and
+// memory of the process and the handler does no access control of its own,
+// so protect it like the detail handlers:
+//
+//	mux.Handle("/health/debug/", s.Protect(debug.Handler("/health/debug")))
+func Handler(prefix string) http.Handler {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	// pprof.Index derives profile names from a hardcoded /debug/pprof/ prefix
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, prefix)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = "/debug" + rest
+		r2.URL.RawPath = ""
+		mux.ServeHTTP(w, r2)
+	})
+}
@@ -0,0 +1,118 @@
+package simplehealth
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// ErrUnavailable marks a check that could not collect its data at all, e.g.
+// load.Avg or the process list failing on a restricted /proc in a hardened
+// container, as opposed to a threshold being crossed.
+var ErrUnavailable = errors.New("unavailable")
+
+func unavailable(what string, err error) error {
+	return fmt.Errorf("%s %w: %v", what, ErrUnavailable, err)
+}
+
+// ErrNotApplicable marks a check that cannot work on this platform at all,
+// e.g. CheckMDRaid on macOS.
+var ErrNotApplicable = errors.New("not applicable on " + runtime.GOOS)
+
+// notApplicableError is reported as ErrNotApplicable with WithSoftFail only,
+// otherwise the check reports fallback, nil to pass.
+type notApplicableError struct {
+	what     string
+	fallback error
+}
+
+func (e *notApplicableError) Error() string { return e.what + " " + ErrNotApplicable.Error() }
+func (e *notApplicableError) Unwrap() error { return ErrNotApplicable }
+
+func notApplicable(what string, fallback error) error {
+	return &notApplicableError{what: what, fallback: fallback}
+}
+
+// withoutSoftFail replaces a not applicable error by its fallback.
+func withoutSoftFail(err error) error {
+	var na *notApplicableError
+	if errors.As(err, &na) {
+		return na.fallback
+	}
+	return err
+}
+
+// linuxOnly guards checks that read Linux specific /proc or /sys files. They
+// report ErrUnavailable elsewhere.
+func linuxOnly(what string) error {
+	if runtime.GOOS == "linux" {
+		return nil
+	}
+	return notApplicable(what, unavailable(what, errors.New("needs Linux")))
+}
+
+// linuxOnlyOrPass is linuxOnly for checks that pass elsewhere.
+func linuxOnlyOrPass(what string) error {
+	if runtime.GOOS == "linux" {
+		return nil
+	}
+	return notApplicable(what, nil)
+}
+
+// WithSoftFail reports checks that are not applicable on this platform with
+// StatusNotApplicable, so a mixed fleet returns the same checks everywhere.
+// Without it they keep their usual result there: they pass or report
+// ErrUnavailable.
+func WithSoftFail() Option {
+	return func(s *SimpleHealth) {
+		s.softFail = true
+	}
+}
+
+// ErrPartial marks a check that passed on part of its data only, e.g. when
+// CheckOpenFiles ran out of time walking the processes. It is reported as a
+// warning instead of failing.
+var ErrPartial = errors.New("incomplete")
+
+func partial(what string) error {
+	return fmt.Errorf("%w: %s", ErrPartial, what)
+}
+
+// SkipUnavailable reports the check as skipped with a warning instead of
+// failed when its data is unavailable.
+func SkipUnavailable() CheckOption {
+	return func(c *check) {
+		c.skipUnavailable = true
+	}
+}
+
+// WithSkipUnavailable is SkipUnavailable for all checks.
+func WithSkipUnavailable() Option {
+	return func(s *SimpleHealth) {
+		s.skipUnavailable = true
+	}
+}
+
+func warningsOf(results []CheckResult) []string {
+	var warnings []string
+	for _, r := range results {
+		switch {
+		case r.Status == StatusSkip:
+			warnings = append(warnings, fmt.Sprintf("%s skipped: %v", r.Name, r.Err))
+		case r.Status == StatusWarn && (errors.Is(r.Err, ErrPartial) || errors.Is(r.Err, ErrStale)):
+			warnings = append(warnings, fmt.Sprintf("%s %v", r.Name, r.Err))
+		case r.Status == StatusWarn:
+			warnings = append(warnings, fmt.Sprintf("%s failed (observed): %v", r.Name, r.Err))
+		}
+	}
+	return warnings
+}
+
+// Observe runs and records the check but reports a failure only as a
+// warning, to try new checks in production before they can fail the
+// endpoint.
+func Observe() CheckOption {
+	return func(c *check) {
+		c.observe = true
+	}
+}
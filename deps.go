@@ -0,0 +1,71 @@
+package simplehealth
+
+import "fmt"
+
+// DependsOn skips the check when one of the named checks fails, so
+// an outage shows up once at its root instead of at every dependent, e.g.
+// AddCheck(checkAPI, DependsOn("CheckDNS", "CheckTCP")).
+func DependsOn(names ...string) CheckOption {
+	return func(c *check) {
+		c.dependsOn = append(c.dependsOn, names...)
+	}
+}
+
+// schedule starts dependencies first, so WithConcurrency does not fill up
+// with waiting dependents. Unknown and cyclic dependencies end up in bad.
+func schedule(checks []check) (order []int, index map[string]int, bad map[int]error) {
+	index = make(map[string]int, len(checks))
+	for i, c := range checks {
+		index[c.name] = i
+	}
+	bad = make(map[int]error)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(checks))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visiting:
+			return fmt.Errorf("dependency cycle at %s", checks[i].name)
+		case visited:
+			return nil
+		}
+		state[i] = visiting
+		var cycle error
+		for _, name := range checks[i].dependsOn {
+			j, ok := index[name]
+			if !ok {
+				bad[i] = fmt.Errorf("depends on unknown check %s", name)
+				break
+			}
+			if cycle = visit(j); cycle != nil {
+				bad[i] = cycle
+				break
+			}
+		}
+		state[i] = visited
+		order = append(order, i)
+		return cycle
+	}
+	for i := range checks {
+		visit(i)
+	}
+	return order, index, bad
+}
+
+// dependencyResult waits for the dependencies of c and returns a skipped
+// result when one of them failed.
+func (c *check) dependencyResult(index map[string]int, done []chan struct{}, results []CheckResult) (CheckResult, bool) {
+	for _, name := range c.dependsOn {
+		i := index[name]
+		<-done[i]
+		if results[i].Failed() {
+			return CheckResult{Name: c.name, Status: StatusSkip, Severity: c.severity, Err: fmt.Errorf("depends on %s which failed", name)}, true
+		}
+	}
+	return CheckResult{}, false
+}
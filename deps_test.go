@@ -0,0 +1,39 @@
+package simplehealth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDependencyResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   Status
+		wantSkip bool
+	}{
+		{"pass", StatusPass, false},
+		{"fail", StatusFail, true},
+		{"warn", StatusWarn, false},
+		{"skip", StatusSkip, false},
+		{"not applicable", StatusNotApplicable, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := []CheckResult{{Name: "db", Status: tt.status}}
+			if tt.status == StatusFail {
+				results[0].Err = errors.New("down")
+			}
+			done := []chan struct{}{make(chan struct{})}
+			close(done[0])
+			c := &check{name: "api", dependsOn: []string{"db"}, severity: SeverityWarning}
+
+			r, skipped := c.dependencyResult(map[string]int{"db": 0}, done, results)
+			if skipped != tt.wantSkip {
+				t.Fatalf("skipped = %v, want %v", skipped, tt.wantSkip)
+			}
+			if skipped && (r.Name != "api" || r.Status != StatusSkip || r.Severity != SeverityWarning || r.Err == nil) {
+				t.Errorf("result = %+v, want a skipped api with warning severity and an error", r)
+			}
+		})
+	}
+}
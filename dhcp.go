@@ -0,0 +1,134 @@
+package simplehealth
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// dhclientLeaseGlobs are where Debian and Red Hat style systems keep the ISC
+// dhclient leases.
+var dhclientLeaseGlobs = []string{"/var/lib/dhcp/dhclient*.leases", "/var/lib/dhclient/*.lease*"}
+
+type dhcpLease struct {
+	iface         string
+	renew, expire time.Time
+	expireNever   bool
+}
+
+// CheckDHCPLease fails when the dhclient lease of one of the interfaces
+// expires within minRemaining while its renewal time has passed, meaning
+// the DHCP server did not answer the renewal and the address will be lost.
+// Interfaces without a lease in the dhclient lease files fail as well.
+func CheckDHCPLease(minRemaining time.Duration, interfaces ...string) ValidatedCheck {
+	return validatedClock(func(clock Clock) error {
+		if err := linuxOnly("dhclient leases"); err != nil {
+			return err
+		}
+		leases, err := readDHCPLeases()
+		if err != nil {
+			return err
+		}
+		now := clock.Now()
+		var errs []error
+		for _, iface := range interfaces {
+			l, ok := leases[iface]
+			switch {
+			case !ok:
+				errs = append(errs, fmt.Errorf("no DHCP lease for %s", iface))
+			case l.expireNever:
+			case !now.Before(l.expire):
+				errs = append(errs, withHint(fmt.Errorf("DHCP lease of %s expired %s ago", iface, now.Sub(l.expire).Round(time.Second)), hintDHCP))
+			case now.After(l.renew) && l.expire.Sub(now) < minRemaining:
+				errs = append(errs, withHint(fmt.Errorf("DHCP lease of %s expires in %s, renewal due since %s", iface,
+					l.expire.Sub(now).Round(time.Second), now.Sub(l.renew).Round(time.Second)), hintDHCP))
+			}
+		}
+		return errors.Join(errs...)
+	}, func() error {
+		switch {
+		case minRemaining < 0:
+			return fmt.Errorf("minRemaining must not be negative, got %s", minRemaining)
+		case len(interfaces) == 0:
+			return errors.New("no interfaces configured")
+		}
+		return nil
+	})
+}
+
+// readDHCPLeases returns the newest lease per interface. dhclient appends
+// leases, so later ones win.
+func readDHCPLeases() (map[string]dhcpLease, error) {
+	var files []string
+	for _, glob := range dhclientLeaseGlobs {
+		matches, err := filepath.Glob(glob)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return nil, unavailable("dhclient leases", fmt.Errorf("no lease files at %s", strings.Join(dhclientLeaseGlobs, ", ")))
+	}
+
+	leases := make(map[string]dhcpLease)
+	for _, name := range files {
+		if err := parseDHCPLeases(name, leases); err != nil {
+			return nil, err
+		}
+	}
+	return leases, nil
+}
+
+// parseDHCPLeases reads the ISC format, with times in UTC:
+//
+//	lease {
+//	  interface "eth0";
+//	  renew 2 2026/10/13 18:12:03;
+//	  expire 3 2026/10/14 05:39:57;
+//	}
+func parseDHCPLeases(name string, leases map[string]dhcpLease) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var cur *dhcpLease
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(strings.TrimSpace(scanner.Text()), ";")
+		fields := strings.Fields(line)
+		switch {
+		case line == "lease {":
+			cur = &dhcpLease{}
+		case line == "}" && cur != nil:
+			if cur.iface != "" {
+				if old, ok := leases[cur.iface]; !ok || cur.expireNever || cur.expire.After(old.expire) {
+					leases[cur.iface] = *cur
+				}
+			}
+			cur = nil
+		case cur == nil || len(fields) < 2:
+		case fields[0] == "interface":
+			cur.iface = strings.Trim(fields[1], `"`)
+		case fields[0] == "expire" && fields[1] == "never":
+			cur.expireNever = true
+		case (fields[0] == "renew" || fields[0] == "expire") && len(fields) == 4:
+			t, err := time.Parse("2006/01/02 15:04:05", fields[2]+" "+fields[3])
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			if fields[0] == "renew" {
+				cur.renew = t
+			} else {
+				cur.expire = t
+			}
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,86 @@
+package simplehealth
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// dirSizeCacheTTL is how long CheckDirSize reuses a walk, so a large
+	// directory is not walked on every probe.
+	dirSizeCacheTTL = 5 * time.Minute
+	// dirSizeMaxFiles bounds a walk, beyond it the size is a lower bound.
+	dirSizeMaxFiles = 1_000_000
+)
+
+// CheckDirSize fails when the files under path take more than maxBytes,
+// e.g. a runaway /var/log or a cache that is never pruned. Walks are cached
+// for 5 minutes and stop after a million files, in which case the check
+// warns as incomplete unless the budget is already exceeded.
+func CheckDirSize(path string, maxBytes int64) ValidatedCheck {
+	var (
+		mu      sync.Mutex
+		walked  time.Time
+		size    int64
+		capped  bool
+		walkErr error
+	)
+
+	return validatedClock(func(clock Clock) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if now := clock.Now(); walked.IsZero() || now.Sub(walked) > dirSizeCacheTTL {
+			size, capped, walkErr = dirSize(path, dirSizeMaxFiles)
+			walked = now
+		}
+		switch {
+		case walkErr != nil:
+			return walkErr
+		case size > maxBytes:
+			return withHint(fmt.Errorf("%s holds %s, budget %s", path, formatBytes(float64(size)), formatBytes(float64(maxBytes))), fmt.Sprintf(hintDirSize, path))
+		case capped:
+			return partial(fmt.Sprintf("%s has more than %d files, counted %s", path, dirSizeMaxFiles, formatBytes(float64(size))))
+		}
+		return nil
+	}, func() error {
+		if maxBytes <= 0 {
+			return fmt.Errorf("maxBytes must be positive, got %d", maxBytes)
+		}
+		return validatePath(path)
+	})
+}
+
+var errTooManyFiles = errors.New("too many files")
+
+// dirSize sums the sizes of the regular files under root without following
+// symlinks. Files that vanish or cannot be read during the walk are skipped.
+func dirSize(root string, maxFiles int) (size int64, capped bool, err error) {
+	files := 0
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if files++; files > maxFiles {
+			return errTooManyFiles
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	if errors.Is(err, errTooManyFiles) {
+		return size, true, nil
+	}
+	return size, false, err
+}
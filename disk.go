@@ -0,0 +1,350 @@
+package simplehealth
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// CheckFilesystemWritable fails when a mount has been remounted read-only,
+// which typically happens after I/O errors while CheckDisk still passes.
+// Without arguments all mounts considered by CheckDisk are inspected. Given
+// mountpoints (or directories on them) are additionally probed by creating
+// and discarding a temporary file.
+func CheckFilesystemWritable(mountpoints ...string) ValidatedCheck {
+	return validated(func() error {
+		parts, err := disk.Partitions(false)
+		if err != nil {
+			return unavailable("partitions", err)
+		}
+
+		for _, part := range parts {
+			if len(mountpoints) > 0 && !slices.Contains(mountpoints, part.Mountpoint) {
+				continue
+			}
+			if len(mountpoints) == 0 && skipPartition(part) {
+				continue
+			}
+			if slices.Contains(part.Opts, "ro") {
+				return withHint(fmt.Errorf("filesystem %s (%s) is mounted read-only", part.Mountpoint, part.Device), hintReadOnly)
+			}
+		}
+
+		for _, dir := range mountpoints {
+			if err := writeProbe(dir); err != nil {
+				return withHint(fmt.Errorf("filesystem %s is not writable: %w", dir, err), hintReadOnly)
+			}
+		}
+		return nil
+	}, func() error {
+		for _, dir := range mountpoints {
+			if err := validatePath(dir); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func createTempProbe(dir string) error {
+	f, err := os.CreateTemp(dir, ".simplehealth-probe-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write([]byte("probe")); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// CheckDiskPath is CheckDisk for the filesystem path lives on only, e.g.
+// /var/lib/myapp, failing when its bytes or inodes are fuller than maxPerc
+// (a fraction).
+func CheckDiskPath(path string, maxPerc float64) ValidatedCheck {
+	return validated(func() error {
+		// resolving stats every component of path, which hangs on a dead
+		// mount just like statfs
+		usage, err := boundedUsage(path, func() (*disk.UsageStat, error) {
+			resolved, err := filepath.EvalSymlinks(path)
+			if err == nil {
+				resolved, err = filepath.Abs(resolved)
+			}
+			if err != nil {
+				return nil, err
+			}
+			return disk.Usage(resolved)
+		})
+		if err != nil {
+			return err
+		}
+		mountpoint := mountpointOf(usage.Path)
+		var errs []error
+		if usage.UsedPercent >= 100*maxPerc {
+			errs = append(errs, &DiskFullError{Mountpoint: mountpoint, Resource: "bytes", Percent: usage.UsedPercent})
+		}
+		if usage.InodesTotal > 0 && usage.InodesUsedPercent >= 100*maxPerc {
+			errs = append(errs, &DiskFullError{Mountpoint: mountpoint, Resource: "inodes", Percent: usage.InodesUsedPercent})
+		}
+		return joinDiskErrors(errs)
+	}, func() error {
+		if err := validateFraction("maxPerc", maxPerc); err != nil {
+			return err
+		}
+		return validatePath(path)
+	})
+}
+
+// mountpointOf returns the longest mountpoint containing path, or path
+// itself when the partitions cannot be listed.
+func mountpointOf(path string) string {
+	parts, err := disk.Partitions(true)
+	if err != nil {
+		return path
+	}
+	best := ""
+	for _, part := range parts {
+		mp := part.Mountpoint
+		if len(mp) > len(best) && (path == mp || strings.HasPrefix(path, strings.TrimSuffix(mp, string(filepath.Separator))+string(filepath.Separator))) {
+			best = mp
+		}
+	}
+	if best == "" {
+		return path
+	}
+	return best
+}
+
+// diskPredictSamples is how many samples CheckDiskPredict keeps per mount,
+// spread over its window.
+const diskPredictSamples = 60
+
+type diskSample struct {
+	time time.Time
+	used uint64
+}
+
+// CheckDiskPredict fails when a mount will be full within window at its
+// growth rate over the last window (linear extrapolation), which catches
+// runaway logs long before CheckDisk's threshold. Until the check has run for
+// a window the rate is over the time since its first run. Samples are kept
+// at most window/60 apart, so frequent runs do not shorten the span.
+func CheckDiskPredict(window time.Duration) ValidatedCheck {
+	var (
+		mu      sync.Mutex
+		samples = make(map[string][]diskSample)
+	)
+
+	return validatedClock(func(clock Clock) error {
+		parts, err := disk.Partitions(false)
+		if err != nil {
+			return unavailable("partitions", err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		now := clock.Now()
+		var errs []error
+		for _, part := range parts {
+			if skipPartition(part) {
+				continue
+			}
+			usage, err := mountUsage(part.Mountpoint)
+			if err != nil || usage.Total == 0 {
+				continue
+			}
+
+			s := samples[part.Mountpoint]
+			if len(s) == 0 || now.Sub(s[len(s)-1].time) >= window/diskPredictSamples {
+				s = append(s, diskSample{now, usage.Used})
+			}
+			// keep the newest sample at least window old as the oldest
+			for len(s) > 1 && now.Sub(s[1].time) >= window {
+				s = s[1:]
+			}
+			s = s[max(0, len(s)-diskPredictSamples-1):]
+			samples[part.Mountpoint] = s
+
+			oldest := s[0]
+			elapsed := now.Sub(oldest.time)
+			if usage.Used <= oldest.used || elapsed <= 0 {
+				continue
+			}
+			rate := float64(usage.Used-oldest.used) / elapsed.Seconds()
+			if full := time.Duration(float64(usage.Free) / rate * float64(time.Second)); full < window {
+				errs = append(errs, withHint(fmt.Errorf("disk %s will be full in %s at %s/h", part.Mountpoint, full.Round(time.Minute), formatBytes(rate*3600)), fmt.Sprintf(hintDiskBytes, part.Mountpoint)))
+			}
+		}
+		return errors.Join(errs...)
+	}, func() error {
+		return validatePositive("window", window)
+	})
+}
+
+func formatBytes(b float64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%.0fB", b)
+	}
+	exp := 0
+	for n := b / unit; n >= unit && exp < 4; n /= unit {
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", b/math.Pow(unit, float64(exp+1)), "KMGTP"[exp])
+}
+
+var (
+	diskstatsPath = "/proc/diskstats"
+	sysBlockPath  = "/sys/block"
+)
+
+type diskIO struct {
+	ios   uint64 // reads and writes completed
+	ticks uint64 // ms spent on them
+}
+
+// CheckDiskLatency fails when the average I/O latency (iostat's await) of a
+// disk since the previous run exceeds maxAwait. Slow disks pass the other disk
+// checks while making everything on the host slow. The first run only records
+// a baseline.
+func CheckDiskLatency(maxAwait time.Duration) ValidatedCheck {
+	var (
+		mu   sync.Mutex
+		prev map[string]diskIO
+	)
+
+	return validated(func() error {
+		if err := linuxOnly("disk stats"); err != nil {
+			return err
+		}
+		stats, err := readDiskstats()
+		if err != nil {
+			return unavailable("disk stats", err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		last := prev
+		prev = stats
+
+		var errs []error
+		for name, cur := range stats {
+			old, ok := last[name]
+			if !ok {
+				continue
+			}
+			ios := delta(cur.ios, old.ios)
+			if ios == 0 {
+				continue
+			}
+			if await := time.Duration(delta(cur.ticks, old.ticks)) * time.Millisecond / time.Duration(ios); await > maxAwait {
+				errs = append(errs, fmt.Errorf("disk %s await is %s over %d I/Os, want less than %s", name, await, ios, maxAwait))
+			}
+		}
+		return errors.Join(errs...)
+	}, func() error {
+		return validatePositive("maxAwait", maxAwait)
+	})
+}
+
+// readDiskstats returns the counters of whole disks, skipping partitions and
+// virtual devices like loop and ram.
+func readDiskstats() (map[string]diskIO, error) {
+	f, err := os.Open(diskstatsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stats := make(map[string]diskIO)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// major minor name reads merged sectors ms_reading writes merged sectors ms_writing ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 11 {
+			continue
+		}
+		name := fields[2]
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") || strings.HasPrefix(name, "zram") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(sysBlockPath, name)); err != nil {
+			continue
+		}
+		var n [4]uint64
+		for i, field := range []string{fields[3], fields[6], fields[7], fields[10]} {
+			if n[i], err = strconv.ParseUint(field, 10, 64); err != nil {
+				return nil, fmt.Errorf("unexpected content in %s: %q", diskstatsPath, scanner.Text())
+			}
+		}
+		stats[name] = diskIO{ios: n[0] + n[2], ticks: n[1] + n[3]}
+	}
+	return stats, scanner.Err()
+}
+
+// mountTimeout bounds a statfs call, which blocks forever on a dead NFS
+// server.
+var mountTimeout = 2 * time.Second
+
+var (
+	pendingMu    sync.Mutex
+	pendingStats = make(map[string]bool)
+)
+
+// mountUsage is disk.Usage bounded by mountTimeout. A hung call keeps its
+// goroutine and later calls for mountpoint fail until it returns.
+func mountUsage(mountpoint string) (*disk.UsageStat, error) {
+	return boundedUsage(mountpoint, func() (*disk.UsageStat, error) {
+		return disk.Usage(mountpoint)
+	})
+}
+
+// boundedUsage runs usage for mountpoint like mountUsage.
+func boundedUsage(mountpoint string, usage func() (*disk.UsageStat, error)) (*disk.UsageStat, error) {
+	pendingMu.Lock()
+	if pendingStats[mountpoint] {
+		pendingMu.Unlock()
+		return nil, unresponsive(mountpoint)
+	}
+	pendingStats[mountpoint] = true
+	pendingMu.Unlock()
+
+	type result struct {
+		usage *disk.UsageStat
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		u, err := usage()
+		pendingMu.Lock()
+		delete(pendingStats, mountpoint)
+		pendingMu.Unlock()
+		done <- result{u, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.usage, r.err
+	case <-time.After(mountTimeout):
+		return nil, unresponsive(mountpoint)
+	}
+}
+
+// ErrUnresponsive marks a mountpoint whose statfs did not return in time.
+var ErrUnresponsive = errors.New("mountpoint unresponsive")
+
+func unresponsive(mountpoint string) error {
+	return withHint(fmt.Errorf("%s %w: no answer within %s", mountpoint, ErrUnresponsive, mountTimeout), fmt.Sprintf(hintUnresponsive, mountpoint))
+}
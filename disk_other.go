@@ -0,0 +1,7 @@
+//go:build !linux
+
+package simplehealth
+
+func writeProbe(dir string) error {
+	return createTempProbe(dir)
+}
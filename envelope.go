@@ -0,0 +1,39 @@
+package simplehealth
+
+import "os"
+
+// Envelope maps the JSON response onto an existing health schema, e.g.
+// Envelope{DataKey: "data", ErrorsKey: "failures", HostKey: "host"}.
+// Empty fields keep the default layout.
+type Envelope struct {
+	StatusKey string
+	ErrorsKey string
+	HostKey   string
+	DataKey   string
+}
+
+func WithEnvelope(e Envelope) Option {
+	return func(s *SimpleHealth) {
+		s.envelope = e
+	}
+}
+
+func (e Envelope) apply(data map[string]any) map[string]any {
+	rename := func(from, to string) {
+		if v, ok := data[from]; ok && to != "" && to != from {
+			delete(data, from)
+			data[to] = v
+		}
+	}
+	rename("status", e.StatusKey)
+	rename("errors", e.ErrorsKey)
+
+	if e.HostKey != "" {
+		host, _ := os.Hostname()
+		data[e.HostKey] = host
+	}
+	if e.DataKey != "" {
+		return map[string]any{e.DataKey: data}
+	}
+	return data
+}
@@ -0,0 +1,264 @@
+package simplehealth
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileCheck verifies the files matching Glob, e.g. "at least 3 backups,
+// each >1MB, newest <24h old". Zero values disable a constraint. With FS set
+// Glob is an fs.Glob pattern within FS, Clock replaces the system clock.
+type FileCheck struct {
+	Glob     string
+	MaxAge   time.Duration
+	MinSize  int64
+	MinCount int
+
+	FS    fs.FS
+	Clock Clock
+}
+
+func NewFileAgeCheck(glob string, maxAge time.Duration) *FileCheck {
+	return &FileCheck{Glob: glob, MaxAge: maxAge}
+}
+
+func (c *FileCheck) Check() error {
+	entries, err := globFiles(c.FS, c.Glob)
+	if err != nil {
+		return err
+	}
+	return checkFiles(c.Glob, entries, orSystemClock(c.Clock).Now(), c.MaxAge, c.MinSize, c.MinCount)
+}
+
+// globFiles stats the files matching glob in fsys, or on the OS when fsys is nil.
+func globFiles(fsys fs.FS, glob string) ([]fileEntry, error) {
+	var (
+		files []string
+		err   error
+	)
+	if fsys == nil {
+		files, err = filepath.Glob(glob)
+	} else {
+		files, err = fs.Glob(fsys, glob)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]fileEntry, 0, len(files))
+	for _, f := range files {
+		var info fs.FileInfo
+		if fsys == nil {
+			info, err = os.Stat(f)
+		} else {
+			info, err = fs.Stat(fsys, f)
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntry{name: f, size: info.Size(), modTime: info.ModTime()})
+	}
+	return entries, nil
+}
+
+// RemoteLister lists a remote directory or object prefix. *sftp.Client
+// satisfies it as is, S3 style stores need a small adapter.
+type RemoteLister interface {
+	ReadDir(path string) ([]fs.FileInfo, error)
+}
+
+// RemoteFileCheck is FileCheck for off-host storage such as backup
+// destinations. Pattern optionally filters entries by base name.
+type RemoteFileCheck struct {
+	Lister   RemoteLister
+	Path     string
+	Pattern  string
+	MaxAge   time.Duration
+	MinSize  int64
+	MinCount int
+
+	Clock Clock
+}
+
+func NewRemoteFileAgeCheck(lister RemoteLister, path string, maxAge time.Duration) *RemoteFileCheck {
+	return &RemoteFileCheck{Lister: lister, Path: path, MaxAge: maxAge}
+}
+
+func (c *RemoteFileCheck) Check() error {
+	infos, err := c.Lister.ReadDir(c.Path)
+	if err != nil {
+		return fmt.Errorf("list %s: %w", c.Path, err)
+	}
+
+	where := c.Path
+	if c.Pattern != "" {
+		where = path.Join(c.Path, c.Pattern)
+	}
+
+	entries := make([]fileEntry, 0, len(infos))
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+		if c.Pattern != "" {
+			if ok, err := path.Match(c.Pattern, info.Name()); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+		}
+		entries = append(entries, fileEntry{name: path.Join(c.Path, info.Name()), size: info.Size(), modTime: info.ModTime()})
+	}
+	return checkFiles(where, entries, orSystemClock(c.Clock).Now(), c.MaxAge, c.MinSize, c.MinCount)
+}
+
+type fileEntry struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func checkFiles(where string, files []fileEntry, now time.Time, maxAge time.Duration, minSize int64, minCount int) error {
+	if len(files) == 0 {
+		return fmt.Errorf("no files found at %s", where)
+	}
+	if len(files) < minCount {
+		return fmt.Errorf("found %d files at %s, want at least %d", len(files), where, minCount)
+	}
+
+	var newest time.Time
+	for _, f := range files {
+		if f.size < minSize {
+			return fmt.Errorf("%s is %d bytes, want at least %d", f.name, f.size, minSize)
+		}
+		if f.modTime.After(newest) {
+			newest = f.modTime
+		}
+	}
+
+	if age := now.Sub(newest); maxAge > 0 && age > maxAge {
+		return fmt.Errorf("newest file at %s is %s old, want less than %s", where, age.Round(time.Second), maxAge)
+	}
+	return nil
+}
+
+// CheckManifest verifies the files listed in a sha256sum style manifest
+// ("<hex digest>  <file>"). Relative names resolve against the manifest's
+// directory.
+func CheckManifest(manifest string) ValidatedCheck {
+	return validated(func() error {
+		f, err := os.Open(manifest)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		var mismatched, missing []string
+		dir := filepath.Dir(manifest)
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			want, name, ok := strings.Cut(line, " ")
+			if !ok {
+				return fmt.Errorf("invalid line in %s: %q", manifest, line)
+			}
+			// binary mode entries are prefixed with '*'
+			name = strings.TrimPrefix(strings.TrimLeft(name, " "), "*")
+			file := name
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(dir, file)
+			}
+
+			got, err := sha256File(file)
+			if os.IsNotExist(err) {
+				missing = append(missing, name)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if !strings.EqualFold(got, want) {
+				mismatched = append(mismatched, name)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+
+		var problems []string
+		if len(mismatched) > 0 {
+			problems = append(problems, "mismatched: "+strings.Join(mismatched, ", "))
+		}
+		if len(missing) > 0 {
+			problems = append(problems, "missing: "+strings.Join(missing, ", "))
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("manifest %s %s", manifest, strings.Join(problems, "; "))
+		}
+		return nil
+	}, func() error {
+		return validatePath(manifest)
+	})
+}
+
+func sha256File(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// CheckLockFiles fails when a file matching one of the globs (e.g.
+// "/var/lock/cron-*.lock") is older than maxAge, which usually means a job
+// is wedged and silently blocks its successors.
+func CheckLockFiles(maxAge time.Duration, globs ...string) ValidatedCheck {
+	return validated(func() error {
+		for _, glob := range globs {
+			files, err := filepath.Glob(glob)
+			if err != nil {
+				return err
+			}
+			for _, f := range files {
+				info, err := os.Stat(f)
+				if err != nil {
+					// released while we were looking
+					continue
+				}
+				if age := time.Since(info.ModTime()); age > maxAge {
+					return fmt.Errorf("lock %s held for %s, want less than %s", f, age.Round(time.Second), maxAge)
+				}
+			}
+		}
+		return nil
+	}, func() error {
+		if len(globs) == 0 {
+			return errors.New("no globs configured")
+		}
+		for _, glob := range globs {
+			if _, err := filepath.Match(glob, ""); err != nil {
+				return fmt.Errorf("glob %q: %w", glob, err)
+			}
+		}
+		return validatePositive("maxAge", maxAge)
+	})
+}
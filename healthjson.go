@@ -0,0 +1,90 @@
+package simplehealth
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// WithComponentType sets the componentType of the check in the health+json
+// response, e.g. "datastore" or "component". Children default to "component".
+func WithComponentType(t string) CheckOption {
+	return func(c *check) {
+		c.componentType = t
+	}
+}
+
+type healthJSONCheck struct {
+	ComponentType string    `json:"componentType,omitempty"`
+	ObservedValue any       `json:"observedValue,omitempty"`
+	Status        string    `json:"status"`
+	Time          time.Time `json:"time"`
+	Output        string    `json:"output,omitempty"`
+}
+
+// HealthJSONHandler serves the checks as application/health+json from the
+// IETF draft "Health Check Response Format for HTTP APIs"
+// (draft-inadarei-api-health-check). The status and code follow the status
+// policy like Handler, skipped, observed and tolerated failing checks
+// lower a pass to warn.
+func (s *SimpleHealth) HealthJSONHandler(w http.ResponseWriter, r *http.Request) {
+	if s.requireAuth(w, r) {
+		return
+	}
+	results := s.results(r)
+	now := orSystemClock(s.clock).Now()
+
+	status := "pass"
+	var output []string
+	checks := make(map[string][]healthJSONCheck, len(results))
+	for _, r := range results {
+		c := healthJSONCheck{
+			ComponentType: r.componentType,
+			ObservedValue: r.Value,
+			Status:        healthJSONStatus(r.Status),
+			Time:          now,
+		}
+		if r.Err != nil {
+			c.Output = r.Err.Error()
+			output = append(output, r.Name+": "+c.Output)
+		}
+		if r.Status == StatusFail || r.Status == StatusSkip || r.Status == StatusWarn {
+			status = "warn"
+		}
+		checks[r.Name] = []healthJSONCheck{c}
+	}
+
+	code, _ := s.status(results)
+	if code >= 300 {
+		status = "fail"
+	}
+	data := map[string]any{
+		"status": status,
+		"checks": checks,
+	}
+	if host, err := os.Hostname(); err == nil {
+		data["serviceId"] = host
+	}
+	if len(output) > 0 {
+		data["output"] = strings.Join(output, "; ")
+	}
+
+	w.Header().Set("Content-Type", "application/health+json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(data)
+}
+
+func healthJSONStatus(s Status) string {
+	switch s {
+	case StatusFail:
+		return "fail"
+	case StatusSkip, StatusWarn:
+		return "warn"
+	default:
+		return "pass"
+	}
+}
@@ -0,0 +1,39 @@
+package simplehealth
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// heartbeat tracks the runner of Start, whose "runner" check fails when no
+// cycle finished for twice its interval.
+type heartbeat struct {
+	mu       sync.Mutex
+	last     time.Time
+	interval time.Duration
+}
+
+func (h *heartbeat) beat(now time.Time, interval time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last, h.interval = now, interval
+}
+
+func (h *heartbeat) active() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return !h.last.IsZero()
+}
+
+func (h *heartbeat) check(clock Clock) check {
+	return check{name: "runner", fn: func() (any, error) {
+		h.mu.Lock()
+		age, interval := clock.Now().Sub(h.last), h.interval
+		h.mu.Unlock()
+		if age > 2*interval {
+			return age.Seconds(), fmt.Errorf("background runner last finished %s ago, runs every %s", age.Round(time.Millisecond), interval)
+		}
+		return age.Seconds(), nil
+	}}
+}
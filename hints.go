@@ -0,0 +1,107 @@
+package simplehealth
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Remediation hints shipped with the built-in checks. Errors provide them via
+// a Hint() string method, WithHint and SetHint override them.
+const (
+	hintLoad         = "see what is busy with `top -o %CPU` or `ps aux --sort=-%cpu | head`"
+	hintOpenFiles    = "count fds with `ls /proc/<pid>/fd | wc -l`, then fix the leak or raise LimitNOFILE"
+	hintDiskBytes    = "find large files with `du -xh %s | sort -h | tail`"
+	hintDiskInode    = "find directories with many files with `du -x --inodes %s | sort -n | tail`"
+	hintUnresponsive = "check the NFS server or network, or detach the mount with `umount -f -l %s`"
+	hintReadOnly     = "check `dmesg -T` for I/O errors, then fsck and remount the filesystem"
+	hintOOM          = "check `dmesg -T | grep -i oom` and the memory limits of the killed service"
+	hintConntrack    = "find the connection flood with `conntrack -S` or raise net.netfilter.nf_conntrack_max"
+	hintMDRaid       = "inspect the array with `mdadm --detail /dev/mdX` and replace failed members"
+	hintSMART        = "inspect with `smartctl -a %s` and plan a disk replacement"
+	hintSystemFDs    = "find the biggest fd users with `lsof -n | awk '{print $2}' | sort | uniq -c | sort -n | tail` or raise fs.file-max"
+	hintCrashLoop    = "see why it keeps exiting with `journalctl -u %s -n 100`"
+	hintDHCP         = "check that the DHCP server answers with `dhclient -v -1 <interface>`"
+	hintDirSize      = "find what grows with `du -xh --max-depth=2 %s | sort -h | tail`, then prune or rotate it"
+	hintIPv6         = "check the default route with `ip -6 route show default` and that router advertisements arrive, or remove the AAAA records"
+	hintEntropy      = "feed the pool with a hardware RNG, virtio-rng for VMs, or run haveged or rng-tools"
+	hintPressure     = "find the contended resource with `vmstat 1`, `iostat -x 1` or the cpu/memory/io.pressure files of the cgroups"
+)
+
+type hintError struct {
+	err  error
+	hint string
+}
+
+func (e *hintError) Error() string { return e.err.Error() }
+func (e *hintError) Unwrap() error { return e.err }
+func (e *hintError) Hint() string  { return e.hint }
+
+func withHint(err error, hint string) error {
+	if err == nil {
+		return nil
+	}
+	return &hintError{err: err, hint: hint}
+}
+
+// hintOf returns the distinct hints of the joined errors in err, in order.
+func hintOf(err error) string {
+	var hints []string
+	var walk func(error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+		case interface{ Hint() string }:
+			if h := e.Hint(); h != "" && !slices.Contains(hints, h) {
+				hints = append(hints, h)
+			}
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+		default:
+			walk(errors.Unwrap(err))
+		}
+	}
+	walk(err)
+	return strings.Join(hints, "; ")
+}
+
+func (e *DiskFullError) Hint() string {
+	if e.Resource == "inodes" {
+		return fmt.Sprintf(hintDiskInode, e.Mountpoint)
+	}
+	return fmt.Sprintf(hintDiskBytes, e.Mountpoint)
+}
+
+// WithHint sets the remediation hint shown when the check fails, overriding
+// the one a built-in check ships.
+func WithHint(hint string) CheckOption {
+	return func(c *check) {
+		c.hint = hint
+	}
+}
+
+// SetHint overrides the hint of an already registered check, such as one of
+// the defaults.
+func (s *SimpleHealth) SetHint(name, hint string) {
+	s.checksMu.Lock()
+	defer s.checksMu.Unlock()
+	s.checks = slices.Clone(s.checks)
+	for i := range s.checks {
+		if s.checks[i].name == name {
+			s.checks[i].hint = hint
+		}
+	}
+}
+
+func hintsOf(results []CheckResult) map[string]string {
+	hints := make(map[string]string)
+	for _, r := range results {
+		if r.Hint != "" {
+			hints[r.Name] = r.Hint
+		}
+	}
+	return hints
+}
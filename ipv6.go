@@ -0,0 +1,65 @@
+package simplehealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	ipv6ProbeTarget  = "[2606:4700:4700::1111]:443"
+	ipv6ProbeTimeout = 5 * time.Second
+)
+
+// CheckIPv6 fails when name has AAAA records but this host has no IPv6
+// route or cannot connect to probe (host:port, by default a public anycast
+// address on port 443) over IPv6. Broken v6 behind advertised AAAA records
+// makes dual-stack clients wait for their fallback to IPv4. Names without
+// AAAA records pass.
+func CheckIPv6(name, probe string) ValidatedCheck {
+	if probe == "" {
+		probe = ipv6ProbeTarget
+	}
+	return validated(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), ipv6ProbeTimeout)
+		defer cancel()
+
+		addrs, err := net.DefaultResolver.LookupIP(ctx, "ip6", name)
+		var (
+			dnsErr  *net.DNSError
+			addrErr *net.AddrError
+		)
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound || errors.As(err, &addrErr) {
+			// no AAAA records, or only IPv4 addresses in /etc/hosts
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cannot resolve AAAA of %s: %w", name, err)
+		}
+		if len(addrs) == 0 {
+			return nil
+		}
+
+		// a UDP dial only selects the route, nothing is sent
+		conn, err := net.Dial("udp6", probe)
+		if err != nil {
+			return withHint(fmt.Errorf("%s has AAAA %s but there is no IPv6 route: %w", name, addrs[0], err), hintIPv6)
+		}
+		conn.Close()
+
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "tcp6", probe)
+		if err != nil {
+			return withHint(fmt.Errorf("%s has AAAA %s but %s is unreachable over IPv6: %w", name, addrs[0], probe, err), hintIPv6)
+		}
+		return conn.Close()
+	}, func() error {
+		if name == "" {
+			return errors.New("no name configured")
+		}
+		_, _, err := net.SplitHostPort(probe)
+		return err
+	})
+}
@@ -0,0 +1,222 @@
+package simplehealth
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var (
+	kmsgPath         = "/dev/kmsg"
+	uptimePath       = "/proc/uptime"
+	memoryEventsPath = "/sys/fs/cgroup/memory.events"
+	fileNrPath       = "/proc/sys/fs/file-nr"
+	entropyPath      = "/proc/sys/kernel/random/entropy_avail"
+)
+
+// CheckOOMKills fails when the kernel OOM killer fired within window. It reads
+// /dev/kmsg when permitted and otherwise falls back to the oom_kill counter in
+// the cgroup v2 memory.events file, which only detects kills between runs.
+func CheckOOMKills(window time.Duration) ValidatedCheck {
+	var (
+		mu        sync.Mutex
+		lastCount int64 = -1
+		lastKill  time.Time
+	)
+
+	return validated(func() error {
+		if err := linuxOnly("OOM kill detection"); err != nil {
+			return err
+		}
+		kills, kmsgErr := recentOOMKills(window)
+		if kmsgErr == nil {
+			if len(kills) > 0 {
+				return withHint(fmt.Errorf("%d OOM kills in the last %s, latest: %s", len(kills), window, kills[len(kills)-1]), hintOOM)
+			}
+			return nil
+		}
+
+		count, err := cgroupOOMKills()
+		if err != nil {
+			return fmt.Errorf("cannot read OOM events: %v; %v", kmsgErr, err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if lastCount >= 0 && count > lastCount {
+			lastKill = time.Now()
+		}
+		lastCount = count
+		if !lastKill.IsZero() && time.Since(lastKill) < window {
+			return withHint(fmt.Errorf("cgroup OOM kill %s ago (%d total)", time.Since(lastKill).Round(time.Second), count), hintOOM)
+		}
+		return nil
+	}, func() error {
+		return validatePositive("window", window)
+	})
+}
+
+func recentOOMKills(window time.Duration) ([]string, error) {
+	uptime, err := readUptime()
+	if err != nil {
+		return nil, err
+	}
+	since := uptime - window
+
+	// Use raw syscalls: an *os.File would park on the poller instead of
+	// returning EAGAIN at the end of the ring buffer.
+	fd, err := syscall.Open(kmsgPath, syscall.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.Close(fd)
+
+	var kills []string
+	buf := make([]byte, 8192)
+	for {
+		n, err := syscall.Read(fd, buf)
+		if errors.Is(err, syscall.EPIPE) {
+			// records were overwritten while reading, continue with the next one
+			continue
+		}
+		if errors.Is(err, syscall.EAGAIN) || n == 0 {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// format: prio,seq,usec,flags;message\n
+		header, msg, ok := bytes.Cut(buf[:n], []byte(";"))
+		if !ok {
+			continue
+		}
+		fields := strings.Split(string(header), ",")
+		if len(fields) < 3 {
+			continue
+		}
+		usec, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil || time.Duration(usec)*time.Microsecond < since {
+			continue
+		}
+		msg, _, _ = bytes.Cut(msg, []byte("\n"))
+		if bytes.Contains(msg, []byte("Killed process")) {
+			kills = append(kills, string(msg))
+		}
+	}
+	return kills, nil
+}
+
+func readUptime() (time.Duration, error) {
+	data, err := os.ReadFile(uptimePath)
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("unexpected content in %s", uptimePath)
+	}
+	secs, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
+
+func cgroupOOMKills() (int64, error) {
+	f, err := os.Open(memoryEventsPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if v, ok := strings.CutPrefix(scanner.Text(), "oom_kill "); ok {
+			return strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no oom_kill counter in %s", memoryEventsPath)
+}
+
+// CheckSystemFDs fails when more than maxPerc (a fraction) of the system-wide
+// file handle limit (fs.file-max) is allocated. Unlike CheckOpenFiles this
+// catches exhaustion spread over many processes.
+func CheckSystemFDs(maxPerc float64) ValidatedCheck {
+	return validated(func() error {
+		if err := linuxOnly("fs.file-nr"); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(fileNrPath)
+		if err != nil {
+			return err
+		}
+		// format: allocated unused max
+		fields := strings.Fields(string(data))
+		if len(fields) != 3 {
+			return fmt.Errorf("unexpected content in %s: %q", fileNrPath, data)
+		}
+		allocated, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return err
+		}
+		unused, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return err
+		}
+		limit, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil || limit == 0 {
+			return err
+		}
+		used := allocated - min(unused, allocated)
+		if usage := float64(used) / float64(limit); usage > maxPerc {
+			return withHint(fmt.Errorf("system uses %d%% of fs.file-max (%d of %d)", int(usage*100), used, limit), hintSystemFDs)
+		}
+		return nil
+	}, func() error {
+		return validateFraction("maxPerc", maxPerc)
+	})
+}
+
+// CheckEntropy fails when the kernel entropy pool holds fewer than minBits,
+// which on older kernels and VMs without a hardware RNG silently stalls
+// reads from /dev/random and with them TLS handshakes. Since Linux 5.18 the
+// pool always reports 256 bits, so the check passes there.
+func CheckEntropy(minBits int) ValidatedCheck {
+	return validated(func() error {
+		if err := linuxOnly("entropy pool"); err != nil {
+			return err
+		}
+		bits, err := readProcInt(entropyPath)
+		if err != nil {
+			return err
+		}
+		if bits < int64(minBits) {
+			return withHint(fmt.Errorf("entropy pool has %d bits, want at least %d", bits, minBits), hintEntropy)
+		}
+		return nil
+	}, func() error {
+		if minBits <= 0 {
+			return fmt.Errorf("minBits must be positive, got %d", minBits)
+		}
+		return nil
+	})
+}
+
+func readProcInt(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
@@ -0,0 +1,37 @@
+package simplehealth
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type lbProfile struct {
+	// runTimeout stays below the default health check timeout of the LB
+	runTimeout time.Duration
+}
+
+var lbProfiles = map[string]lbProfile{
+	"aws-alb": {runTimeout: 4 * time.Second},         // HealthCheckTimeoutSeconds 5
+	"gcp":     {runTimeout: 4 * time.Second},         // timeoutSec 5
+	"haproxy": {runTimeout: 1500 * time.Millisecond}, // timeout check defaults to inter 2s
+	"nginx":   {runTimeout: 800 * time.Millisecond},  // upstream check timeout 1000ms
+}
+
+// WithLBProfile configures the handler for a load balancer: "aws-alb",
+// "gcp", "haproxy" or "nginx". All of them answer 503 only for critical
+// failures, so warnings do not take the host out of rotation, fail checks
+// that would not finish before the LB gives up (WithRunTimeout), and default
+// to the terse body unless ?verbose is given. Later options override these.
+func WithLBProfile(name string) Option {
+	return func(s *SimpleHealth) {
+		p, ok := lbProfiles[name]
+		if !ok {
+			s.configErrs = append(s.configErrs, fmt.Errorf("unknown LB profile %q", name))
+			return
+		}
+		s.policy = BasicPolicy{Codes: map[Severity]int{SeverityCritical: http.StatusServiceUnavailable}, IgnoreWarnings: true}
+		s.runTimeout = p.runTimeout
+		s.terse = true
+	}
+}
@@ -0,0 +1,34 @@
+//go:build !windows
+
+package simplehealth
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/shirou/gopsutil/v3/load"
+)
+
+// Measure returns the load per CPU over Window.
+func (c *LoadCheck) Measure() (float64, error) {
+	if err := c.Validate(); err != nil {
+		return 0, err
+	}
+	avg, err := load.Avg()
+	if err != nil {
+		return 0, unavailable("load average", err)
+	}
+	numCPU := float64(runtime.NumCPU())
+	got := map[int]float64{1: avg.Load1, 5: avg.Load5, 15: avg.Load15}[c.Window] / numCPU
+	if c.Sustained {
+		load1, load15 := avg.Load1/numCPU, avg.Load15/numCPU
+		if load1 > c.MaxPerCPU && load15 > c.MaxPerCPU {
+			return got, withHint(fmt.Errorf("sustained high load per cpu: load1 %f and load15 %f", load1, load15), hintLoad)
+		}
+		return got, nil
+	}
+	if got > c.MaxPerCPU {
+		return got, withHint(fmt.Errorf("high load%d per cpu: %f", c.Window, got), hintLoad)
+	}
+	return got, nil
+}
@@ -0,0 +1,24 @@
+package simplehealth
+
+import (
+	"fmt"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+// Measure uses the CPU usage since the previous run, Windows has no load
+// average.
+func (c *LoadCheck) Measure() (float64, error) {
+	if err := c.Validate(); err != nil {
+		return 0, err
+	}
+	perc, err := cpu.Percent(0, false)
+	if err != nil || len(perc) == 0 {
+		return 0, unavailable("cpu usage", err)
+	}
+	got := perc[0] / 100
+	if got > c.MaxPerCPU {
+		return got, withHint(fmt.Errorf("high cpu usage: %.0f%%", perc[0]), hintLoad)
+	}
+	return got, nil
+}
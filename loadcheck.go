@@ -0,0 +1,37 @@
+package simplehealth
+
+import "fmt"
+
+// LoadCheck fails when the load average over Window minutes (1, 5 or 15)
+// divided by the number of CPUs exceeds MaxPerCPU. With Sustained it only
+// fails when both load1 and load15 exceed MaxPerCPU, so a short spike passes
+// and only overload that lasts fails. On Windows, which has no load
+// average, it compares the CPU usage instead and ignores Window and Sustained.
+type LoadCheck struct {
+	Window    int
+	MaxPerCPU float64
+	Sustained bool
+}
+
+func NewLoadCheck() *LoadCheck {
+	return &LoadCheck{Window: 5, MaxPerCPU: maxLoad}
+}
+
+func measureLoad() (float64, error) {
+	return NewLoadCheck().Measure()
+}
+
+func (c *LoadCheck) Check() error {
+	_, err := c.Measure()
+	return err
+}
+
+func (c *LoadCheck) Validate() error {
+	switch {
+	case c.Window != 1 && c.Window != 5 && c.Window != 15:
+		return fmt.Errorf("Window must be 1, 5 or 15 minutes, got %d", c.Window)
+	case c.MaxPerCPU <= 0:
+		return fmt.Errorf("MaxPerCPU must be positive, got %v", c.MaxPerCPU)
+	}
+	return nil
+}
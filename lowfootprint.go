@@ -0,0 +1,25 @@
+package simplehealth
+
+// WithLowFootprint trims simplehealth for Raspberry Pis and edge gateways
+// where it should cost close to nothing: CheckOpenFiles, which walks every
+// process, is replaced by CheckOwnOpenFiles, no history is kept unless
+// WithStore is given, availability is not tracked and checks run one at a
+// time unless WithConcurrency says otherwise.
+func WithLowFootprint() Option {
+	return func(s *SimpleHealth) {
+		s.lowFootprint = true
+		if s.concurrency == 0 {
+			s.concurrency = 1
+		}
+		if s.RemoveCheck(funcName(CheckOpenFiles)) == nil {
+			s.AddCheck(CheckOwnOpenFiles)
+		}
+	}
+}
+
+// discardStore keeps no history.
+type discardStore struct{}
+
+func (discardStore) Save(string, Sample, int) error     { return nil }
+func (discardStore) Load() (map[string][]Sample, error) { return map[string][]Sample{}, nil }
+func (discardStore) History(string) ([]Sample, error)   { return nil, nil }
@@ -0,0 +1,69 @@
+package simplehealth
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	mdstatPath = "/proc/mdstat"
+
+	mdMembersRe = regexp.MustCompile(`\[(\d+)/(\d+)\] \[([U_]+)\]`)
+	mdRecoverRe = regexp.MustCompile(`recovery = *([\d.]+%)`)
+)
+
+// CheckMDRaid fails when a software RAID array in /proc/mdstat is inactive,
+// degraded, recovering onto a replacement member or has failed devices.
+// Hosts without md arrays pass.
+func CheckMDRaid() error {
+	if err := linuxOnlyOrPass("md raid"); err != nil {
+		return err
+	}
+	f, err := os.Open(mdstatPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var array string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// md0 : active raid1 sdb1[1] sda1[0](F)
+		if name, rest, ok := strings.Cut(line, " : "); ok && strings.HasPrefix(name, "md") {
+			array = name
+			fields := strings.Fields(rest)
+			if len(fields) > 0 && fields[0] == "inactive" {
+				return withHint(fmt.Errorf("raid %s is inactive", array), hintMDRaid)
+			}
+			for _, dev := range fields {
+				if strings.HasSuffix(dev, "(F)") {
+					return withHint(fmt.Errorf("raid %s has failed device %s", array, strings.TrimSuffix(dev, "(F)")), hintMDRaid)
+				}
+			}
+			continue
+		}
+		if array == "" || !strings.HasPrefix(line, " ") {
+			continue
+		}
+
+		// 976630336 blocks super 1.2 [2/1] [U_]
+		if m := mdMembersRe.FindStringSubmatch(line); m != nil {
+			if m[1] != m[2] || strings.Contains(m[3], "_") {
+				return withHint(fmt.Errorf("raid %s is degraded, %s of %s members up [%s]", array, m[2], m[1], m[3]), hintMDRaid)
+			}
+		}
+		// [==>...]  recovery = 12.6% (123/456) finish=10.0min speed=100K/sec
+		if m := mdRecoverRe.FindStringSubmatch(line); m != nil {
+			return withHint(fmt.Errorf("raid %s is rebuilding, recovery at %s", array, m[1]), hintMDRaid)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,85 @@
+package simplehealth
+
+import (
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// MountRule matches mounts by glob on their mountpoint, device and
+// filesystem type, all of which must match. An empty pattern matches
+// anything, * matches any run of characters including slashes. Skip decides
+// whether matching mounts are skipped or kept.
+type MountRule struct {
+	Mountpoint string
+	Device     string
+	Fstype     string
+	Skip       bool
+}
+
+// DefaultMountRules skip loop devices, snaps, /boot, devfs and the macOS
+// system volumes, which share the APFS container of the data volume.
+var DefaultMountRules = []MountRule{
+	{Device: "*loop*", Skip: true},
+	{Mountpoint: "*/snap/*", Skip: true},
+	{Mountpoint: "*/boot*", Skip: true},
+	{Device: "*devfs*", Skip: true},
+	{Mountpoint: "/System/Volumes/Data"},
+	{Mountpoint: "/System/Volumes/*", Skip: true},
+}
+
+// VirtualMountRules skip memory backed and image filesystems, which rarely
+// matter for capacity, e.g. DiskCheck{Mounts: append(VirtualMountRules,
+// DefaultMountRules...)}.
+var VirtualMountRules = []MountRule{
+	{Fstype: "tmpfs", Skip: true},
+	{Fstype: "devtmpfs", Skip: true},
+	{Fstype: "overlay", Skip: true},
+	{Fstype: "squashfs", Skip: true},
+}
+
+func (r MountRule) matches(part disk.PartitionStat) bool {
+	return globMatch(r.Mountpoint, part.Mountpoint) && globMatch(r.Device, part.Device) && globMatch(r.Fstype, part.Fstype)
+}
+
+// skipMount applies the first rule that matches part, mounts without a
+// matching rule are kept.
+func skipMount(rules []MountRule, part disk.PartitionStat) bool {
+	for _, r := range rules {
+		if r.matches(part) {
+			return r.Skip
+		}
+	}
+	return false
+}
+
+func skipPartition(part disk.PartitionStat) bool {
+	return skipMount(DefaultMountRules, part)
+}
+
+// globMatch matches s against a MountRule pattern byte by byte, going back
+// to the last * on a mismatch.
+func globMatch(pattern, s string) bool {
+	if pattern == "" {
+		return true
+	}
+	p, i := 0, 0
+	star, next := -1, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == s[i]):
+			p++
+			i++
+		case p < len(pattern) && pattern[p] == '*':
+			star, next = p, i
+			p++
+		case star >= 0:
+			next++
+			p, i = star+1, next
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
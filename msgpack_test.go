@@ -0,0 +1,44 @@
+package simplehealth
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMarshalMsgpack(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want []byte
+	}{
+		{"nil", nil, []byte{0xc0}},
+		{"false", false, []byte{0xc2}},
+		{"true", true, []byte{0xc3}},
+		{"positive fixint", 5, []byte{0x05}},
+		{"negative fixint", -3, []byte{0xfd}},
+		{"int64", 300, []byte{0xd3, 0, 0, 0, 0, 0, 0, 0x01, 0x2c}},
+		{"negative int64", -33, []byte{0xd3, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xdf}},
+		{"float64", 1.5, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{"fixstr", "ok", []byte{0xa2, 'o', 'k'}},
+		{"str8", strings.Repeat("x", 32), append([]byte{0xd9, 32}, strings.Repeat("x", 32)...)},
+		{"str16", strings.Repeat("x", 256), append([]byte{0xda, 0x01, 0x00}, strings.Repeat("x", 256)...)},
+		{"fixarray", []any{1, "a"}, []byte{0x92, 0x01, 0xa1, 'a'}},
+		{"array16", make([]any, 16), append([]byte{0xdc, 0, 16}, bytes.Repeat([]byte{0xc0}, 16)...)},
+		{"sorted fixmap", map[string]any{"b": 2, "a": 1}, []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02}},
+		{"struct as JSON", struct {
+			Status string `json:"status"`
+		}{"VERYHAPPY"}, append([]byte{0x81, 0xa6, 's', 't', 'a', 't', 'u', 's', 0xa9}, "VERYHAPPY"...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := marshalMsgpack(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("marshalMsgpack(%v) = % x, want % x", tt.v, got, tt.want)
+			}
+		})
+	}
+}
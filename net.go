@@ -0,0 +1,133 @@
+package simplehealth
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	psnet "github.com/shirou/gopsutil/v3/net"
+)
+
+// CheckNetInterfaces fails when errors plus drops on an interface exceed
+// maxRate (a fraction, e.g. 0.01) of the packets it handled since the
+// previous run. The first run only records a baseline.
+func CheckNetInterfaces(maxRate float64) ValidatedCheck {
+	var (
+		mu   sync.Mutex
+		prev map[string]psnet.IOCountersStat
+	)
+
+	return validated(func() error {
+		counters, err := psnet.IOCounters(true)
+		if err != nil {
+			return unavailable("interface counters", err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		last := prev
+		prev = make(map[string]psnet.IOCountersStat, len(counters))
+		for _, cur := range counters {
+			prev[cur.Name] = cur
+		}
+
+		for _, cur := range counters {
+			if cur.Name == "lo" {
+				continue
+			}
+			old, ok := last[cur.Name]
+			if !ok {
+				continue
+			}
+			packets := delta(cur.PacketsRecv, old.PacketsRecv) + delta(cur.PacketsSent, old.PacketsSent)
+			bad := delta(cur.Errin, old.Errin) + delta(cur.Errout, old.Errout) +
+				delta(cur.Dropin, old.Dropin) + delta(cur.Dropout, old.Dropout)
+			if packets == 0 {
+				continue
+			}
+			if rate := float64(bad) / float64(packets); rate > maxRate {
+				return fmt.Errorf("interface %s has %d errors/drops in %d packets (%.2f%%)", cur.Name, bad, packets, 100*rate)
+			}
+		}
+		return nil
+	}, func() error {
+		return validateFraction("maxRate", maxRate)
+	})
+}
+
+var (
+	conntrackCountPath = "/proc/sys/net/netfilter/nf_conntrack_count"
+	conntrackMaxPath   = "/proc/sys/net/netfilter/nf_conntrack_max"
+)
+
+// CheckConntrack fails when the netfilter connection tracking table is
+// fuller than maxPerc (a fraction). Once it is full the kernel silently drops
+// new connections. Hosts without conntrack loaded pass.
+func CheckConntrack(maxPerc float64) ValidatedCheck {
+	return validated(func() error {
+		if err := linuxOnlyOrPass("conntrack"); err != nil {
+			return err
+		}
+		count, err := readProcInt(conntrackCountPath)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		limit, err := readProcInt(conntrackMaxPath)
+		if err != nil {
+			return err
+		}
+		if limit <= 0 {
+			return nil
+		}
+		if usage := float64(count) / float64(limit); usage > maxPerc {
+			return withHint(fmt.Errorf("conntrack table %d%% full (%d of %d)", int(usage*100), count, limit), hintConntrack)
+		}
+		return nil
+	}, func() error {
+		return validateFraction("maxPerc", maxPerc)
+	})
+}
+
+// CheckListening fails when nothing is bound to the local port for proto
+// ("tcp", "tcp6", "udp", ...), catching processes that are alive but lost or
+// never opened their socket.
+func CheckListening(port int, proto string) ValidatedCheck {
+	return validated(func() error {
+		conns, err := psnet.Connections(proto)
+		if err != nil {
+			return unavailable("socket table", err)
+		}
+		for _, c := range conns {
+			if int(c.Laddr.Port) != port {
+				continue
+			}
+			// udp sockets have no LISTEN state, an unconnected bound socket is the equivalent
+			if c.Status == "LISTEN" || (strings.HasPrefix(proto, "udp") && c.Raddr.Port == 0) {
+				return nil
+			}
+		}
+		return fmt.Errorf("nothing listening on %s port %d", proto, port)
+	}, func() error {
+		if port <= 0 || port > 65535 {
+			return fmt.Errorf("port must be between 1 and 65535, got %d", port)
+		}
+		if !slices.Contains([]string{"tcp", "tcp4", "tcp6", "udp", "udp4", "udp6"}, proto) {
+			return fmt.Errorf("unknown protocol %q", proto)
+		}
+		return nil
+	})
+}
+
+// delta tolerates counter resets, e.g. when an interface is recreated.
+func delta(cur, old uint64) uint64 {
+	if cur < old {
+		return cur
+	}
+	return cur - old
+}
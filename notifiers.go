@@ -0,0 +1,115 @@
+package simplehealth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SlackNotifier posts a compact message (host, failing checks, durations) to
+// a Slack or Mattermost incoming webhook.
+type SlackNotifier struct {
+	WebhookURL string
+	Channel    string
+	Username   string
+	Client     *http.Client
+}
+
+func (n SlackNotifier) Notify(ctx context.Context, e Event) error {
+	var b strings.Builder
+	if e.Status == "healthy" {
+		fmt.Fprintf(&b, ":large_green_circle: *%s* is healthy again", slackEscaper.Replace(e.Host))
+	} else {
+		fmt.Fprintf(&b, ":red_circle: *%s* is unhealthy", slackEscaper.Replace(e.Host))
+	}
+	for _, f := range e.Failures {
+		fmt.Fprintf(&b, "\n• `%s` %s (%s)", f.Name, slackEscaper.Replace(f.Err.Error()), f.Duration.Round(time.Millisecond))
+	}
+
+	payload := map[string]string{"text": b.String()}
+	if n.Channel != "" {
+		payload["channel"] = n.Channel
+	}
+	if n.Username != "" {
+		payload["username"] = n.Username
+	}
+	return postJSON(ctx, n.Client, n.WebhookURL, nil, payload)
+}
+
+// slackEscaper escapes the control characters of Slack mrkdwn, which has no
+// backslash escapes.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// DiscordNotifier posts a message to a Discord webhook.
+type DiscordNotifier struct {
+	WebhookURL string
+	Username   string
+	Client     *http.Client
+}
+
+func (n DiscordNotifier) Notify(ctx context.Context, e Event) error {
+	payload := map[string]string{"content": markdownMessage(e, "**%s**")}
+	if n.Username != "" {
+		payload["username"] = n.Username
+	}
+	return postJSON(ctx, n.Client, n.WebhookURL, nil, payload)
+}
+
+const telegramAPIURL = "https://api.telegram.org"
+
+// TelegramNotifier sends a message through the Telegram bot API to ChatID,
+// which is a numeric id or "@channelname". URL overrides the API endpoint.
+type TelegramNotifier struct {
+	Token  string
+	ChatID string
+	URL    string
+	Client *http.Client
+}
+
+func (n TelegramNotifier) Notify(ctx context.Context, e Event) error {
+	endpoint := n.URL
+	if endpoint == "" {
+		endpoint = telegramAPIURL
+	}
+	err := postJSON(ctx, n.Client, endpoint+"/bot"+n.Token+"/sendMessage", nil, map[string]string{
+		"chat_id":    n.ChatID,
+		"text":       markdownMessage(e, "*%s*"),
+		"parse_mode": "Markdown",
+	})
+	if err != nil && n.Token != "" {
+		// the token is part of the URL, which net/http and post put in errors
+		return redactedError{err, n.Token}
+	}
+	return err
+}
+
+// redactedError hides secret in the message of err.
+type redactedError struct {
+	err    error
+	secret string
+}
+
+func (e redactedError) Error() string {
+	return strings.ReplaceAll(e.err.Error(), e.secret, "REDACTED")
+}
+
+func (e redactedError) Unwrap() error { return e.err }
+
+var markdownEscaper = strings.NewReplacer("_", `\_`, "*", `\*`, "`", "\\`", "[", `\[`)
+
+// markdownMessage lists the failures of e, bold formats the host.
+func markdownMessage(e Event, bold string) string {
+	var b strings.Builder
+	host := fmt.Sprintf(bold, markdownEscaper.Replace(e.Host))
+	if e.Status == "healthy" {
+		fmt.Fprintf(&b, "✅ %s is healthy again", host)
+	} else {
+		fmt.Fprintf(&b, "🔴 %s is unhealthy", host)
+	}
+	for _, f := range e.Failures {
+		fmt.Fprintf(&b, "\n• `%s` %s (%s)", f.Name, markdownEscaper.Replace(f.Err.Error()), f.Duration.Round(time.Millisecond))
+	}
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package simplehealth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// NtfyNotifier publishes to an ntfy topic URL, e.g. "https://ntfy.sh/mytopic".
+// The severity maps to the ntfy priority, recoveries use the default one.
+type NtfyNotifier struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+var ntfyPriority = map[Severity]string{
+	SeverityCritical: "urgent",
+	SeverityWarning:  "high",
+	SeverityInfo:     "default",
+}
+
+func (n NtfyNotifier) Notify(ctx context.Context, e Event) error {
+	title, message := pushMessage(e)
+	headers := map[string]string{
+		"Title":    title,
+		"Priority": ntfyPriority[e.Severity],
+	}
+	if e.Status == "healthy" {
+		headers["Tags"] = "white_check_mark"
+	} else {
+		headers["Tags"] = "rotating_light"
+	}
+	if n.Token != "" {
+		headers["Authorization"] = "Bearer " + n.Token
+	}
+	return post(ctx, n.Client, n.URL, "text/plain", headers, []byte(message))
+}
+
+// GotifyNotifier sends a message to a Gotify server with an application token.
+type GotifyNotifier struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+var gotifyPriority = map[Severity]int{
+	SeverityCritical: 8,
+	SeverityWarning:  5,
+	SeverityInfo:     2,
+}
+
+func (n GotifyNotifier) Notify(ctx context.Context, e Event) error {
+	title, message := pushMessage(e)
+	return postJSON(ctx, n.Client, strings.TrimSuffix(n.URL, "/")+"/message", map[string]string{"X-Gotify-Key": n.Token}, map[string]any{
+		"title":    title,
+		"message":  message,
+		"priority": gotifyPriority[e.Severity],
+	})
+}
+
+func pushMessage(e Event) (title, message string) {
+	if e.Status == "healthy" {
+		return e.Host + " is healthy again", "All checks pass."
+	}
+	lines := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		lines[i] = f.Name + ": " + f.Err.Error()
+	}
+	return e.Host + " is unhealthy (" + strconv.Itoa(len(e.Failures)) + " failing)", strings.Join(lines, "\n")
+}
@@ -0,0 +1,238 @@
+package simplehealth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const notifyTimeout = 10 * time.Second
+
+// Event describes a change of the aggregate health state. Notifiers only
+// receive events on transitions, not on every run. History holds the recent
+// results of the failing checks for trend context.
+type Event struct {
+	Host     string              `json:"host"`
+	Status   string              `json:"status"`
+	Previous string              `json:"previous"`
+	Severity Severity            `json:"severity"`
+	Time     time.Time           `json:"time"`
+	Failures []CheckResult       `json:"failures"`
+	History  map[string][]Sample `json:"history,omitempty"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, e Event) error
+}
+
+// CheckEvent describes a state change of a single check, which happens
+// without a change of the aggregate when a second check fails or one of
+// several recovers.
+type CheckEvent struct {
+	Host     string      `json:"host"`
+	Check    string      `json:"check"`
+	Status   string      `json:"status"`
+	Previous string      `json:"previous"`
+	Time     time.Time   `json:"time"`
+	Result   CheckResult `json:"result"`
+	History  []Sample    `json:"history,omitempty"`
+}
+
+// CheckNotifier is a Notifier that tracks checks separately, e.g. an incident
+// per check. It receives every CheckEvent instead of the aggregate Events.
+type CheckNotifier interface {
+	Notifier
+	NotifyCheck(ctx context.Context, e CheckEvent) error
+}
+
+func WithNotifier(n Notifier) Option {
+	return func(s *SimpleHealth) {
+		s.notifiers = append(s.notifiers, n)
+		if _, ok := n.(CheckNotifier); ok {
+			s.checkNotifiers = true
+		}
+	}
+}
+
+// newCheckEvent must be called with s.mu held.
+func (s *SimpleHealth) newCheckEvent(r CheckResult, wasFailed bool, now time.Time) CheckEvent {
+	host, _ := os.Hostname()
+	e := CheckEvent{
+		Host:     host,
+		Check:    r.Name,
+		Status:   stateName(!r.Failed()),
+		Previous: stateName(!wasFailed),
+		Time:     now,
+		Result:   r,
+	}
+	e.History, _ = s.store.History(r.Name)
+	return e
+}
+
+// notifyQueueSize bounds the notifications waiting for a slow notifier.
+const notifyQueueSize = 64
+
+// notifyQueues deliver the notifications of each notifier one at a time, so
+// a trigger and a later resolve cannot overtake each other.
+type notifyQueues struct {
+	once   sync.Once
+	queues []chan func()
+}
+
+// send queues deliver for notifier i, or drops it when the queue is full.
+func (s *SimpleHealth) send(i int, deliver func()) {
+	s.queues.once.Do(func() {
+		s.queues.queues = make([]chan func(), len(s.notifiers))
+		for j := range s.queues.queues {
+			q := make(chan func(), notifyQueueSize)
+			s.queues.queues[j] = q
+			go func() {
+				for deliver := range q {
+					deliver()
+				}
+			}()
+		}
+	})
+	select {
+	case s.queues.queues[i] <- deliver:
+	default:
+		if s.logger != nil {
+			s.logger.Error("notification dropped, queue full", "notifier", fmt.Sprintf("%T", s.notifiers[i]))
+		}
+	}
+}
+
+func (s *SimpleHealth) notifyChecks(events []CheckEvent) {
+	for i, n := range s.notifiers {
+		cn, ok := n.(CheckNotifier)
+		if !ok {
+			continue
+		}
+		s.send(i, func() {
+			for _, e := range events {
+				ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+				if err := cn.NotifyCheck(ctx, e); err != nil && s.logger != nil {
+					s.logger.Error("notification failed", "notifier", fmt.Sprintf("%T", n), "check", e.Check, "error", err)
+				}
+				cancel()
+			}
+		})
+	}
+}
+
+func (s *SimpleHealth) notify(e Event) {
+	for i, n := range s.notifiers {
+		if _, ok := n.(CheckNotifier); ok {
+			continue
+		}
+		s.send(i, func() {
+			ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+			defer cancel()
+			if err := n.Notify(ctx, e); err != nil && s.logger != nil {
+				s.logger.Error("notification failed", "notifier", fmt.Sprintf("%T", n), "error", err)
+			}
+		})
+	}
+}
+
+// newEvent must be called with s.mu held.
+func (s *SimpleHealth) newEvent(healthy, wasHealthy bool, results []CheckResult) Event {
+	host, _ := os.Hostname()
+	e := Event{
+		Host:     host,
+		Status:   stateName(healthy),
+		Previous: stateName(wasHealthy),
+		Time:     orSystemClock(s.clock).Now(),
+		Failures: []CheckResult{},
+	}
+	for _, r := range results {
+		if r.Failed() {
+			e.Failures = append(e.Failures, r)
+			if e.History == nil {
+				e.History = make(map[string][]Sample)
+			}
+			e.History[r.Name], _ = s.store.History(r.Name)
+		}
+	}
+	e.Severity = severityOf(e.Failures)
+	return e
+}
+
+func (r CheckResult) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Name       string    `json:"name"`
+		Status     Status    `json:"status"`
+		Severity   string    `json:"severity,omitempty"`
+		Value      any       `json:"value,omitempty"`
+		Threshold  any       `json:"threshold,omitempty"`
+		Error      string    `json:"error,omitempty"`
+		Hint       string    `json:"hint,omitempty"`
+		DurationMS float64   `json:"duration_ms"`
+		Timestamp  time.Time `json:"timestamp"`
+	}{
+		Name:       r.Name,
+		Status:     r.Status,
+		Value:      r.Value,
+		Threshold:  r.threshold,
+		Hint:       r.Hint,
+		DurationMS: float64(r.Duration.Microseconds()) / 1000,
+		Timestamp:  r.Timestamp,
+	}
+	if r.Err != nil {
+		v.Error = r.Err.Error()
+	}
+	if r.Failed() {
+		v.Severity = r.Severity.String()
+	}
+	return json.Marshal(v)
+}
+
+// WebhookNotifier POSTs the Event as JSON to URL.
+type WebhookNotifier struct {
+	URL     string
+	Headers map[string]string
+	Client  *http.Client
+}
+
+func (n WebhookNotifier) Notify(ctx context.Context, e Event) error {
+	return postJSON(ctx, n.Client, n.URL, n.Headers, e)
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return post(ctx, client, url, "application/json", headers, body)
+}
+
+func post(ctx context.Context, client *http.Client, url, contentType string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("POST %s: %s: %s", url, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
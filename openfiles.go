@@ -0,0 +1,74 @@
+//go:build !darwin
+
+package simplehealth
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// openFilesScanBudget bounds the walk over all processes, which can take
+// long with huge process counts or slow /proc reads.
+const openFilesScanBudget = 5 * time.Second
+
+// measureOpenFiles returns the highest open files usage of any process, or
+// what it saw within openFilesScanBudget with ErrPartial.
+func measureOpenFiles() (float64, error) {
+	processes, err := process.Processes()
+	if err != nil {
+		return 0, unavailable("process list", err)
+	}
+
+	var (
+		mu      sync.Mutex
+		highest float64
+		scanned int
+		stop    atomic.Bool
+	)
+	done := make(chan error, 1)
+	go func() {
+		for _, p := range processes {
+			if stop.Load() {
+				return
+			}
+			pname, usage, err := openFilesUsage(p)
+			if errors.Is(err, ErrNotApplicable) {
+				done <- err
+				return
+			}
+			mu.Lock()
+			scanned++
+			if err == nil {
+				highest = max(highest, usage)
+			}
+			mu.Unlock()
+			if err == nil && usage > maxOpenFilesPerc {
+				done <- openFilesError(pname, usage)
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	timer := time.NewTimer(openFilesScanBudget)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		mu.Lock()
+		defer mu.Unlock()
+		if errors.Is(err, ErrNotApplicable) {
+			return 0, err
+		}
+		return highest, err
+	case <-timer.C:
+		stop.Store(true)
+		mu.Lock()
+		defer mu.Unlock()
+		return highest, partial(fmt.Sprintf("scan truncated after %d of %d processes", scanned, len(processes)))
+	}
+}
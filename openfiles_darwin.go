@@ -0,0 +1,65 @@
+package simplehealth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"golang.org/x/sys/unix"
+)
+
+// measureOpenFiles returns the higher of the usage of this process and of
+// kern.maxfiles, macOS hides the limits of other processes.
+func measureOpenFiles() (float64, error) {
+	pname, usage, err := ownOpenFilesUsage()
+	if err != nil {
+		return 0, unavailable("open files", err)
+	}
+	if usage > maxOpenFilesPerc {
+		return usage, openFilesError(pname, usage)
+	}
+
+	num, err := unix.SysctlUint32("kern.num_files")
+	if err != nil {
+		return usage, unavailable("kern.num_files", err)
+	}
+	limit, err := unix.SysctlUint32("kern.maxfiles")
+	if err != nil || limit == 0 {
+		return usage, unavailable("kern.maxfiles", err)
+	}
+	system := float64(num) / float64(limit)
+	if system > maxOpenFilesPerc {
+		return system, openFilesError("system", system)
+	}
+	return max(usage, system), nil
+}
+
+func openFilesUsage(p *process.Process) (string, float64, error) {
+	if int(p.Pid) != os.Getpid() {
+		name, _ := p.Name()
+		// macOS does not expose the limits of other processes
+		return fmt.Sprintf("%d/%s", p.Pid, name), 0, notApplicable("open files limit of other processes", unavailable("open files limit", errors.New("not exposed for other processes on macOS")))
+	}
+	return ownOpenFilesUsage()
+}
+
+func ownOpenFilesUsage() (string, float64, error) {
+	name, _ := os.Executable()
+	pname := fmt.Sprintf("%d/%s", os.Getpid(), name)
+
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		return pname, 0, err
+	}
+	if rlimit.Cur == 0 {
+		return pname, 0, nil
+	}
+	fds, err := os.ReadDir("/dev/fd")
+	if err != nil {
+		return pname, 0, err
+	}
+	// ReadDir holds one descriptor itself
+	return pname, float64(len(fds)-1) / float64(rlimit.Cur), nil
+}
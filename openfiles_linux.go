@@ -0,0 +1,65 @@
+package simplehealth
+
+import (
+	"fmt"
+	"syscall"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// openFilesUsage returns the fraction of its open files limit p uses, or 0
+// for processes that should not be judged.
+func openFilesUsage(p *process.Process) (string, float64, error) {
+	user, _ := p.Username()
+	name, _ := p.Name()
+	pname := fmt.Sprintf("%d/%s/%s", p.Pid, user, name)
+
+	rlimits, err := p.Rlimit()
+	if err != nil {
+		return pname, 0, err
+	}
+
+	softLimit := rlimits[syscall.RLIMIT_NOFILE].Soft
+	if softLimit <= 0 {
+		// Skip processes with no file limits
+		return pname, 0, nil
+	}
+
+	if softLimit < 1024 && (user == "root" || user == "sshd") {
+		/*
+			dodge an edge case where sshd sometimes has a limit of 1: cat /proc/$(pgrep sshd -n)/limits
+
+			Data Limit                     Soft Limit           Hard Limit           Units
+				Max cpu time              unlimited            unlimited            seconds
+				Max file size             0                    0                    bytes
+				Max data size             unlimited            unlimited            bytes
+				Max stack size            8388608              unlimited            bytes
+				Max core file size        0                    unlimited            bytes
+				Max resident set          unlimited            unlimited            bytes
+				Max processes             0                    0                    processes
+				Max open files            1                    1                    files
+				Max locked memory         8388608              8388608              bytes
+				Max address space         unlimited            unlimited            bytes
+				Max file locks            unlimited            unlimited            locks
+				Max pending signals       62319                62319                signals
+				Max msgqueue size         819200               819200               bytes
+				Max nice priority         0                    0
+				Max realtime priority     0                    0
+				Max realtime timeout      unlimited            unlimited            us
+		*/
+
+		return pname, 0, nil
+	}
+
+	cur, err := p.NumFDs()
+	if err != nil {
+		return pname, 0, err
+	}
+
+	if cur == 0 || cur > int32(softLimit) {
+		// cannot happen?!
+		return pname, 0, nil
+	}
+
+	return pname, float64(cur) / float64(softLimit), nil
+}
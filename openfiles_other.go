@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !windows
+
+package simplehealth
+
+import (
+	"fmt"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+func openFilesUsage(p *process.Process) (string, float64, error) {
+	return fmt.Sprint(p.Pid), 0, notApplicable("open files limit", nil)
+}
@@ -0,0 +1,29 @@
+package simplehealth
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"golang.org/x/sys/windows"
+)
+
+var procGetProcessHandleCount = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetProcessHandleCount")
+
+func openFilesUsage(p *process.Process) (string, float64, error) {
+	user, _ := p.Username()
+	name, _ := p.Name()
+	pname := fmt.Sprintf("%d/%s/%s", p.Pid, user, name)
+
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(p.Pid))
+	if err != nil {
+		return pname, 0, err
+	}
+	defer windows.CloseHandle(h)
+
+	var count uint32
+	if ok, _, err := procGetProcessHandleCount.Call(uintptr(h), uintptr(unsafe.Pointer(&count))); ok == 0 {
+		return pname, 0, err
+	}
+	return pname, float64(count) / float64(MaxHandles), nil
+}
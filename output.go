@@ -0,0 +1,124 @@
+package simplehealth
+
+import (
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// accepted picks the response format from the Accept header, JSON unless
+// another is preferred explicitly.
+func accepted(r *http.Request) string {
+	if r == nil {
+		return "application/json"
+	}
+	best, bestQ := "application/json", 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "application/json", "text/plain", "text/html", "application/msgpack", "application/x-protobuf":
+		default:
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > bestQ {
+			best, bestQ = mediaType, q
+		}
+	}
+	return best
+}
+
+// writeText writes "OK" or "FAIL" followed by one line per failure and
+// warning, for curl and shell scripts.
+func writeText(w http.ResponseWriter, code int, results []CheckResult) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(code)
+	if code < 300 {
+		fmt.Fprintln(w, "OK")
+	} else {
+		fmt.Fprintln(w, "FAIL")
+	}
+	for _, err := range errorsOf(results) {
+		fmt.Fprintln(w, err)
+	}
+	for _, warning := range warningsOf(results) {
+		fmt.Fprintln(w, "warning:", warning)
+	}
+}
+
+var dashboard = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"ms": func(d time.Duration) string { return d.Round(time.Millisecond).String() },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{if .OK}}OK{{else}}FAIL{{end}} - health</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+td, th { padding: .3em .8em; text-align: left; border-bottom: 1px solid #ddd; }
+.pass { background: #d4f7d4; }
+.fail { background: #f7d4d4; }
+.skip, .warn { background: #f7f0d4; }
+.not_applicable { color: #888; }
+</style>
+</head>
+<body>
+<h1>{{if .OK}}OK{{else}}FAIL{{end}}</h1>
+<table>
+<tr><th>Check</th><th>Status</th><th>Duration</th><th>Error</th><th>Hint</th></tr>
+{{range .Results}}<tr class="{{.Status}}"><td>{{.Name}}</td><td>{{.Status}}</td><td>{{ms .Duration}}</td><td>{{if .Err}}{{.Err}}{{end}}</td><td>{{.Hint}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func writeHTML(w http.ResponseWriter, code int, results []CheckResult) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+	_ = dashboard.Execute(w, map[string]any{
+		"OK":      code < 300,
+		"Results": results,
+	})
+}
+
+// verbosity reads the verbose query parameter, absent means neither unless
+// defaultTerse.
+func verbosity(r *http.Request, defaultTerse bool) (terse, verbose bool) {
+	if r == nil || r.URL == nil || !r.URL.Query().Has("verbose") {
+		return defaultTerse, false
+	}
+	v, err := strconv.ParseBool(r.URL.Query().Get("verbose"))
+	if err != nil {
+		return defaultTerse, false
+	}
+	return !v, v
+}
+
+func (s *SimpleHealth) addDetails(data map[string]any, results []CheckResult, verbose bool) {
+	if warnings := warningsOf(results); len(warnings) > 0 {
+		data["warnings"] = warnings
+	}
+	s.addChildren(data, results)
+	if s.inProgress() {
+		data["in_progress"] = true
+	}
+	if verbose {
+		data["checks"] = results
+		if a := s.availabilityReport(); a != nil {
+			data["availability"] = a
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package simplehealth
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+const (
+	pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+	opsgenieAlertsURL  = "https://api.opsgenie.com/v2/alerts"
+)
+
+// incidentKey keys incidents on hostname/check so every failing check pages
+// separately.
+func incidentKey(e CheckEvent) string {
+	return e.Host + "/" + e.Check
+}
+
+// PagerDutyNotifier triggers a PagerDuty Events v2 incident per failing check
+// and resolves it once the check recovers, also while other checks still
+// fail.
+type PagerDutyNotifier struct {
+	RoutingKey string
+	URL        string
+	Client     *http.Client
+}
+
+// Notify ignores aggregate events, the incidents follow NotifyCheck.
+func (n PagerDutyNotifier) Notify(context.Context, Event) error {
+	return nil
+}
+
+func (n PagerDutyNotifier) NotifyCheck(ctx context.Context, e CheckEvent) error {
+	endpoint := n.URL
+	if endpoint == "" {
+		endpoint = pagerDutyEventsURL
+	}
+	if !e.Result.Failed() {
+		return postJSON(ctx, n.Client, endpoint, nil, map[string]any{
+			"routing_key":  n.RoutingKey,
+			"event_action": "resolve",
+			"dedup_key":    incidentKey(e),
+		})
+	}
+	f := e.Result
+	return postJSON(ctx, n.Client, endpoint, nil, map[string]any{
+		"routing_key":  n.RoutingKey,
+		"event_action": "trigger",
+		"dedup_key":    incidentKey(e),
+		"payload": map[string]any{
+			"summary":        e.Host + ": " + f.Err.Error(),
+			"source":         e.Host,
+			"component":      f.Name,
+			"severity":       f.Severity.String(),
+			"custom_details": map[string]any{"hint": f.Hint, "history": e.History},
+		},
+	})
+}
+
+// OpsgenieNotifier is PagerDutyNotifier for the Opsgenie Alert API, using the
+// hostname/check key as alert alias.
+type OpsgenieNotifier struct {
+	APIKey string
+	URL    string
+	Client *http.Client
+}
+
+var opsgeniePriority = map[Severity]string{
+	SeverityCritical: "P1",
+	SeverityWarning:  "P3",
+	SeverityInfo:     "P5",
+}
+
+// Notify ignores aggregate events, the alerts follow NotifyCheck.
+func (n OpsgenieNotifier) Notify(context.Context, Event) error {
+	return nil
+}
+
+func (n OpsgenieNotifier) NotifyCheck(ctx context.Context, e CheckEvent) error {
+	endpoint := n.URL
+	if endpoint == "" {
+		endpoint = opsgenieAlertsURL
+	}
+	headers := map[string]string{"Authorization": "GenieKey " + n.APIKey}
+	if !e.Result.Failed() {
+		closeURL := endpoint + "/" + url.PathEscape(incidentKey(e)) + "/close?identifierType=alias"
+		return postJSON(ctx, n.Client, closeURL, headers, map[string]any{})
+	}
+	f := e.Result
+	return postJSON(ctx, n.Client, endpoint, headers, map[string]any{
+		"message":     e.Host + ": " + f.Name + " failed",
+		"alias":       incidentKey(e),
+		"description": f.Err.Error() + "\n\n" + f.Hint,
+		"source":      e.Host,
+		"entity":      f.Name,
+		"priority":    opsgeniePriority[f.Severity],
+	})
+}
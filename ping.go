@@ -0,0 +1,84 @@
+package simplehealth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WithPing pings url after every run, and url+"/fail" when the run failed,
+// with the errors as body. A dead man's switch like healthchecks.io then
+// alerts when the pings stop because the whole host is gone. Combine with
+// Start so runs do not depend on incoming requests.
+func WithPing(url string) Option {
+	return func(s *SimpleHealth) {
+		s.pings = append(s.pings, strings.TrimSuffix(url, "/"))
+	}
+}
+
+// Start runs the checks every interval until ctx is done. Handler then also
+// reports a "runner" check that fails when the loop stops making progress.
+func (s *SimpleHealth) Start(ctx context.Context, interval time.Duration) {
+	clock := orSystemClock(s.clock)
+	s.runner.beat(clock.Now(), interval)
+	go func() {
+		wait := interval
+		for {
+			s.run()
+			if s.adaptive != nil {
+				wait = s.adaptive.next(interval, wait)
+			}
+			s.runner.beat(clock.Now(), wait)
+			select {
+			case <-ctx.Done():
+				s.runner.beat(time.Time{}, 0)
+				return
+			case <-clock.After(wait):
+			}
+		}
+	}()
+}
+
+func (s *SimpleHealth) ping(report Report) {
+	if len(s.pings) == 0 {
+		return
+	}
+	var body strings.Builder
+	suffix := ""
+	if !report.Healthy {
+		suffix = "/fail"
+	}
+	for _, err := range errorsOf(report.Checks) {
+		fmt.Fprintln(&body, err)
+	}
+	for _, url := range s.pings {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+			defer cancel()
+			if err := sendPing(ctx, url+suffix, body.String()); err != nil && s.logger != nil {
+				s.logger.Error("ping failed", "url", url, "error", err)
+			}
+		}()
+	}
+}
+
+func sendPing(ctx context.Context, url, body string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("POST %s: %s", url, resp.Status)
+	}
+	return nil
+}
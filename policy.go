@@ -0,0 +1,64 @@
+package simplehealth
+
+import "net/http"
+
+// StatusPolicy decides the HTTP status code and the status string of a
+// response from the results of a run.
+type StatusPolicy interface {
+	Status(results []CheckResult) (code int, status string)
+}
+
+func WithStatusPolicy(p StatusPolicy) Option {
+	return func(s *SimpleHealth) {
+		s.policy = p
+	}
+}
+
+// BasicPolicy is a configurable StatusPolicy, its zero value is the default
+// of "VERYHAPPY" with 200 and "MUCHSAD" with 500 on any failure.
+type BasicPolicy struct {
+	Healthy   string
+	Unhealthy string
+	Code      int // returned when unhealthy
+	// Codes overrides Code by the severity of the most severe failure.
+	Codes map[Severity]int
+
+	// MinFailures is the number of failing checks needed to be unhealthy.
+	MinFailures int
+	// IgnoreWarnings only counts failures of SeverityCritical checks.
+	IgnoreWarnings bool
+}
+
+func (p BasicPolicy) Status(results []CheckResult) (int, string) {
+	var failures []CheckResult
+	for _, r := range results {
+		if r.Failed() && (!p.IgnoreWarnings || r.Severity == SeverityCritical) {
+			failures = append(failures, r)
+		}
+	}
+	if len(failures) == 0 || len(failures) < p.MinFailures {
+		return http.StatusOK, orDefault(p.Healthy, "VERYHAPPY")
+	}
+	code, ok := p.Codes[severityOf(failures)]
+	if !ok {
+		code = p.Code
+	}
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+	return code, orDefault(p.Unhealthy, "MUCHSAD")
+}
+
+func (s *SimpleHealth) status(results []CheckResult) (int, string) {
+	if s.policy == nil {
+		return BasicPolicy{}.Status(results)
+	}
+	return s.policy.Status(results)
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,43 @@
+package simplehealth
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestBasicPolicyStatus(t *testing.T) {
+	pass := CheckResult{Name: "a", Status: StatusPass}
+	critical := CheckResult{Name: "b", Status: StatusFail, Severity: SeverityCritical, Err: errors.New("down")}
+	warning := CheckResult{Name: "c", Status: StatusFail, Severity: SeverityWarning, Err: errors.New("slow")}
+	skipped := CheckResult{Name: "d", Status: StatusSkip}
+
+	tests := []struct {
+		name       string
+		policy     BasicPolicy
+		results    []CheckResult
+		wantCode   int
+		wantStatus string
+	}{
+		{"no results", BasicPolicy{}, nil, http.StatusOK, "VERYHAPPY"},
+		{"all pass", BasicPolicy{}, []CheckResult{pass, skipped}, http.StatusOK, "VERYHAPPY"},
+		{"critical failure", BasicPolicy{}, []CheckResult{pass, critical}, http.StatusInternalServerError, "MUCHSAD"},
+		{"warning failure", BasicPolicy{}, []CheckResult{warning}, http.StatusInternalServerError, "MUCHSAD"},
+		{"custom strings and code", BasicPolicy{Healthy: "ok", Unhealthy: "down", Code: http.StatusServiceUnavailable}, []CheckResult{critical}, http.StatusServiceUnavailable, "down"},
+		{"custom healthy string", BasicPolicy{Healthy: "ok"}, []CheckResult{pass}, http.StatusOK, "ok"},
+		{"ignore warnings", BasicPolicy{IgnoreWarnings: true}, []CheckResult{warning}, http.StatusOK, "VERYHAPPY"},
+		{"ignore warnings with critical", BasicPolicy{IgnoreWarnings: true}, []CheckResult{warning, critical}, http.StatusInternalServerError, "MUCHSAD"},
+		{"below min failures", BasicPolicy{MinFailures: 2}, []CheckResult{critical}, http.StatusOK, "VERYHAPPY"},
+		{"at min failures", BasicPolicy{MinFailures: 2}, []CheckResult{critical, warning}, http.StatusInternalServerError, "MUCHSAD"},
+		{"code by severity", BasicPolicy{Codes: map[Severity]int{SeverityWarning: 299}}, []CheckResult{warning}, 299, "MUCHSAD"},
+		{"code by most severe", BasicPolicy{Codes: map[Severity]int{SeverityWarning: 299}, Code: 503}, []CheckResult{warning, critical}, 503, "MUCHSAD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, status := tt.policy.Status(tt.results)
+			if code != tt.wantCode || status != tt.wantStatus {
+				t.Errorf("Status() = %d %q, want %d %q", code, status, tt.wantCode, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package simplehealth
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var pressureDir = "/proc/pressure"
+
+// PressureLimit is the share of time, in percent, that tasks may stall on a
+// resource, averaged over 10 and 60 seconds. Zero disables an average.
+type PressureLimit struct {
+	Avg10 float64
+	Avg60 float64
+}
+
+// CheckPressure fails when the "some" pressure stall information of a
+// resource ("cpu", "memory" or "io") exceeds its limit, a more direct
+// saturation signal than the load average. Kernels without PSI (before 4.20
+// or booted with psi=0) report ErrUnavailable, so the check can be skipped
+// with SkipUnavailable, e.g.
+//
+//	s.Add(simplehealth.CheckPressure(map[string]simplehealth.PressureLimit{
+//		"memory": {Avg10: 10, Avg60: 5},
+//		"io":     {Avg60: 20},
+//	}), simplehealth.SkipUnavailable())
+func CheckPressure(limits map[string]PressureLimit) ValidatedCheck {
+	return validated(func() error {
+		if err := linuxOnly("pressure stall information"); err != nil {
+			return err
+		}
+		var errs []error
+		for _, resource := range slices.Sorted(maps.Keys(limits)) {
+			limit := limits[resource]
+			if !slices.Contains([]string{"cpu", "memory", "io"}, resource) {
+				errs = append(errs, fmt.Errorf("unknown pressure resource %q", resource))
+				continue
+			}
+			avg10, avg60, err := readPressure(resource)
+			if errors.Is(err, os.ErrNotExist) || errors.Is(err, errors.ErrUnsupported) {
+				return unavailable("pressure stall information", err)
+			}
+			if err != nil {
+				return err
+			}
+			switch {
+			case limit.Avg10 > 0 && avg10 > limit.Avg10:
+				errs = append(errs, withHint(fmt.Errorf("%s pressure avg10 %.2f%% above %.2f%%", resource, avg10, limit.Avg10), hintPressure))
+			case limit.Avg60 > 0 && avg60 > limit.Avg60:
+				errs = append(errs, withHint(fmt.Errorf("%s pressure avg60 %.2f%% above %.2f%%", resource, avg60, limit.Avg60), hintPressure))
+			}
+		}
+		return errors.Join(errs...)
+	}, func() error {
+		var errs []error
+		for _, resource := range slices.Sorted(maps.Keys(limits)) {
+			limit := limits[resource]
+			if !slices.Contains([]string{"cpu", "memory", "io"}, resource) {
+				errs = append(errs, fmt.Errorf("unknown pressure resource %q", resource))
+			}
+			if limit.Avg10 < 0 || limit.Avg10 > 100 || limit.Avg60 < 0 || limit.Avg60 > 100 {
+				errs = append(errs, fmt.Errorf("%s pressure limits must be percentages between 0 and 100", resource))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// readPressure parses the "some" line of a PSI file:
+//
+//	some avg10=0.12 avg60=0.08 avg300=0.02 total=1234567
+func readPressure(resource string) (avg10, avg60 float64, err error) {
+	f, err := os.Open(filepath.Join(pressureDir, resource))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "some" {
+			continue
+		}
+		for _, field := range fields[1:] {
+			key, value, _ := strings.Cut(field, "=")
+			switch key {
+			case "avg10":
+				avg10, err = strconv.ParseFloat(value, 64)
+			case "avg60":
+				avg60, err = strconv.ParseFloat(value, 64)
+			}
+			if err != nil {
+				return 0, 0, fmt.Errorf("%s pressure: %w", resource, err)
+			}
+		}
+		return avg10, avg60, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+	return 0, 0, fmt.Errorf("%s pressure: no \"some\" line", resource)
+}
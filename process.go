@@ -0,0 +1,112 @@
+package simplehealth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// CheckPIDFile fails when the PID file is missing, points to a process that
+// no longer exists, or to a process not named name (e.g. after a crash the
+// PID got reused). An empty name only checks liveness.
+func CheckPIDFile(path, name string) ValidatedCheck {
+	return validated(func() error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		pid, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 32)
+		if err != nil || pid <= 0 {
+			return fmt.Errorf("pid file %s has invalid content %q", path, strings.TrimSpace(string(data)))
+		}
+
+		exists, err := process.PidExists(int32(pid))
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("pid file %s is stale, process %d is not running", path, pid)
+		}
+		if name == "" {
+			return nil
+		}
+
+		p, err := process.NewProcess(int32(pid))
+		if err != nil {
+			return err
+		}
+		got, err := p.Name()
+		if err != nil {
+			return err
+		}
+		if got != name {
+			return fmt.Errorf("pid file %s points to %d/%s, want %s", path, pid, got, name)
+		}
+		return nil
+	}, func() error {
+		return validateDir(path)
+	})
+}
+
+// CheckOwnOpenFiles is CheckOpenFiles for the current process only, which
+// needs no privileges and no walk over /proc.
+func CheckOwnOpenFiles() error {
+	p, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return err
+	}
+	return checkOpenFilesOf(p)
+}
+
+// CheckProcessOpenFiles is CheckOpenFiles for the process with the given pid
+// or for all processes with the given name.
+func CheckProcessOpenFiles(nameOrPid string) ValidatedCheck {
+	return validated(func() error {
+		if pid, err := strconv.ParseInt(nameOrPid, 10, 32); err == nil {
+			p, err := process.NewProcess(int32(pid))
+			if err != nil {
+				return fmt.Errorf("process %d: %w", pid, err)
+			}
+			return checkOpenFilesOf(p)
+		}
+
+		processes, err := process.Processes()
+		if err != nil {
+			return unavailable("process list", err)
+		}
+		found := false
+		for _, p := range processes {
+			if name, _ := p.Name(); name != nameOrPid {
+				continue
+			}
+			found = true
+			if err := checkOpenFilesOf(p); err != nil {
+				return err
+			}
+		}
+		if !found {
+			return fmt.Errorf("no process named %s", nameOrPid)
+		}
+		return nil
+	}, func() error {
+		if nameOrPid == "" {
+			return errors.New("no process name or pid configured")
+		}
+		return nil
+	})
+}
+
+func checkOpenFilesOf(p *process.Process) error {
+	pname, usage, err := openFilesUsage(p)
+	if err != nil {
+		return fmt.Errorf("cannot read open files of %s: %w", pname, err)
+	}
+	if usage > maxOpenFilesPerc {
+		return openFilesError(pname, usage)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package simplehealth
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"reflect"
+)
+
+// writeProtobuf writes the response as the Response message of
+// simplehealth.proto, encoded by hand to avoid a protobuf dependency.
+func writeProtobuf(w http.ResponseWriter, r *http.Request, code int, status string, results []CheckResult, terse, verbose bool) {
+	var b []byte
+	b = appendProtoString(b, 1, status)
+	b = appendProtoVarint(b, 2, uint64(code))
+	if !terse {
+		for _, err := range errorsOf(results) {
+			b = appendProtoString(b, 3, err.Error())
+		}
+		for _, warning := range warningsOf(results) {
+			b = appendProtoString(b, 4, warning)
+		}
+	}
+	if verbose {
+		for _, r := range results {
+			b = appendProtoBytes(b, 5, protoCheck(r))
+		}
+	}
+	writeEncoded(w, r, code, "application/x-protobuf", b)
+}
+
+func protoCheck(r CheckResult) []byte {
+	var b []byte
+	b = appendProtoString(b, 1, r.Name)
+	b = appendProtoString(b, 2, string(r.Status))
+	if r.Failed() {
+		b = appendProtoString(b, 3, r.Severity.String())
+	}
+	if v, ok := toFloat(r.Value); ok {
+		b = appendProtoDouble(b, 4, v)
+	} else if r.Value != nil {
+		if data, err := json.Marshal(r.Value); err == nil {
+			b = appendProtoString(b, 6, string(data))
+		}
+	}
+	if v, ok := toFloat(r.threshold); ok {
+		b = appendProtoDouble(b, 5, v)
+	}
+	if r.Err != nil {
+		b = appendProtoString(b, 7, r.Err.Error())
+	}
+	b = appendProtoString(b, 8, r.Hint)
+	if ms := float64(r.Duration.Microseconds()) / 1000; ms != 0 {
+		b = appendProtoDouble(b, 9, ms)
+	}
+	if !r.Timestamp.IsZero() {
+		b = appendProtoVarint(b, 10, uint64(r.Timestamp.UnixNano()))
+	}
+	return b
+}
+
+func toFloat(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	}
+	return 0, false
+}
+
+func appendProtoVarint(b []byte, field int, v uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3)
+	return binary.AppendUvarint(b, v)
+}
+
+func appendProtoDouble(b []byte, field int, v float64) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|1)
+	return binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+}
+
+func appendProtoBytes(b []byte, field int, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendProtoString omits empty strings like proto3 does.
+func appendProtoString(b []byte, field int, v string) []byte {
+	if v == "" {
+		return b
+	}
+	return appendProtoBytes(b, field, []byte(v))
+}
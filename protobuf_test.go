@@ -0,0 +1,80 @@
+package simplehealth
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProtoFields(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []byte
+		want []byte
+	}{
+		{"varint", appendProtoVarint(nil, 2, 500), []byte{0x10, 0xf4, 0x03}},
+		{"double", appendProtoDouble(nil, 4, 1.5), []byte{0x21, 0, 0, 0, 0, 0, 0, 0xf8, 0x3f}},
+		{"string", appendProtoString(nil, 1, "ok"), []byte{0x0a, 0x02, 'o', 'k'}},
+		{"empty string", appendProtoString(nil, 1, ""), nil},
+		{"bytes", appendProtoBytes(nil, 5, []byte{0x08, 0x01}), []byte{0x2a, 0x02, 0x08, 0x01}},
+		{"field above 15", appendProtoVarint(nil, 16, 1), []byte{0x80, 0x01, 0x01}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !bytes.Equal(tt.got, tt.want) {
+				t.Errorf("got % x, want % x", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProtoCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		r    CheckResult
+		want []byte
+	}{
+		{"pass", CheckResult{Name: "a", Status: StatusPass}, []byte{0x0a, 0x01, 'a', 0x12, 0x04, 'p', 'a', 's', 's'}},
+		{"failure with severity and error", CheckResult{Name: "a", Status: StatusFail, Severity: SeverityWarning, Err: errors.New("x")},
+			append(append([]byte{0x0a, 0x01, 'a', 0x12, 0x04, 'f', 'a', 'i', 'l', 0x1a, 0x07}, "warning"...), 0x3a, 0x01, 'x')},
+		{"numeric value", CheckResult{Name: "a", Status: StatusPass, Value: 2},
+			[]byte{0x0a, 0x01, 'a', 0x12, 0x04, 'p', 'a', 's', 's', 0x21, 0, 0, 0, 0, 0, 0, 0, 0x40}},
+		{"other value as JSON", CheckResult{Name: "a", Status: StatusPass, Value: []int{1}},
+			[]byte{0x0a, 0x01, 'a', 0x12, 0x04, 'p', 'a', 's', 's', 0x32, 0x03, '[', '1', ']'}},
+		{"duration in ms", CheckResult{Name: "a", Status: StatusPass, Duration: 1500 * time.Microsecond},
+			[]byte{0x0a, 0x01, 'a', 0x12, 0x04, 'p', 'a', 's', 's', 0x49, 0, 0, 0, 0, 0, 0, 0xf8, 0x3f}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := protoCheck(tt.r); !bytes.Equal(got, tt.want) {
+				t.Errorf("protoCheck() = % x, want % x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteProtobuf(t *testing.T) {
+	results := []CheckResult{{Name: "a", Status: StatusFail, Err: errors.New("x")}}
+	tests := []struct {
+		name           string
+		terse, verbose bool
+		want           []byte
+	}{
+		{"terse", true, false, []byte{0x0a, 0x02, 'n', 'o', 0x10, 0xf4, 0x03}},
+		{"errors", false, false, []byte{0x0a, 0x02, 'n', 'o', 0x10, 0xf4, 0x03, 0x1a, 0x1, 'x'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeProtobuf(w, nil, 500, "no", results, tt.terse, tt.verbose)
+			if w.Code != 500 || w.Header().Get("Content-Type") != "application/x-protobuf" {
+				t.Errorf("got %d %s", w.Code, w.Header().Get("Content-Type"))
+			}
+			if got := w.Body.Bytes(); !bytes.Equal(got, tt.want) {
+				t.Errorf("body = % x, want % x", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package simplehealth
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+)
+
+// PushedReport is the body Pusher posts and Aggregator receives.
+type PushedReport struct {
+	Host   string            `json:"host"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Time   time.Time         `json:"time"`
+	// Interval is the interval of Start in seconds, if it runs, so the
+	// receiver knows when to expect the next report.
+	Interval float64 `json:"interval,omitempty"`
+	Report
+}
+
+// Pusher POSTs the Report of every full run to URL as a PushedReport, for
+// hosts behind NAT that a central monitor cannot scrape. Combine with Start
+// for periodic reports.
+type Pusher struct {
+	URL     string
+	Host    string // defaults to the hostname
+	Labels  map[string]string
+	Headers map[string]string
+	Client  *http.Client
+}
+
+func WithPush(p Pusher) Option {
+	return func(s *SimpleHealth) {
+		s.pushers = append(s.pushers, p)
+	}
+}
+
+func (s *SimpleHealth) push(r Report) {
+	if len(s.pushers) == 0 {
+		return
+	}
+	s.runner.mu.Lock()
+	interval := s.runner.interval
+	if s.runner.last.IsZero() {
+		interval = 0
+	}
+	s.runner.mu.Unlock()
+	report := PushedReport{
+		Time:     orSystemClock(s.clock).Now(),
+		Interval: interval.Seconds(),
+		Report:   r,
+	}
+	for _, p := range s.pushers {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+			defer cancel()
+			if err := p.Push(ctx, report); err != nil && s.logger != nil {
+				s.logger.Error("push failed", "url", p.URL, "error", err)
+			}
+		}()
+	}
+}
+
+// Push posts report with the host and labels of p.
+func (p Pusher) Push(ctx context.Context, report PushedReport) error {
+	report.Host = p.Host
+	if report.Host == "" {
+		report.Host, _ = os.Hostname()
+	}
+	report.Labels = p.Labels
+	return postJSON(ctx, p.Client, p.URL, p.Headers, report)
+}
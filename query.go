@@ -0,0 +1,146 @@
+package simplehealth
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// listQuery selects part of a long list in a response, e.g. the checks of a
+// host with hundreds of children:
+//
+//	?fields=name,status&offset=100&limit=50
+type listQuery struct {
+	fields        []string
+	offset, limit int
+}
+
+func parseListQuery(r *http.Request) (listQuery, error) {
+	var q listQuery
+	if r == nil || r.URL == nil {
+		return q, nil
+	}
+	v := r.URL.Query()
+	if f := v.Get("fields"); f != "" {
+		q.fields = strings.Split(f, ",")
+	}
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"offset", &q.offset}, {"limit", &q.limit}} {
+		if !v.Has(p.name) {
+			continue
+		}
+		n, err := strconv.Atoi(v.Get(p.name))
+		if err != nil || n < 0 {
+			return q, fmt.Errorf("invalid %s %q", p.name, v.Get(p.name))
+		}
+		*p.dst = n
+	}
+	return q, nil
+}
+
+func (q listQuery) empty() bool {
+	return len(q.fields) == 0 && q.offset == 0 && q.limit == 0
+}
+
+// page returns the selected page of items and the total number of items.
+func (q listQuery) page(items any) ([]map[string]any, int, error) {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, 0, err
+	}
+	var list []map[string]any
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, 0, err
+	}
+	total := len(list)
+	list = list[min(q.offset, total):]
+	if q.limit > 0 {
+		list = list[:min(q.limit, len(list))]
+	}
+	return list, total, nil
+}
+
+// pick keeps only the selected fields of every item.
+func (q listQuery) pick(list []map[string]any) {
+	if len(q.fields) == 0 {
+		return
+	}
+	for i, item := range list {
+		selected := make(map[string]any, len(q.fields))
+		for _, f := range q.fields {
+			if v, ok := item[f]; ok {
+				selected[f] = v
+			}
+		}
+		list[i] = selected
+	}
+}
+
+// selectChecks applies q to the verbose checks, and limits the children to
+// the ones on the page.
+func selectChecks(data map[string]any, q listQuery) error {
+	checks, total, err := q.page(data["checks"])
+	if err != nil {
+		return err
+	}
+	if children, ok := data["children"].(map[string]any); ok && (q.offset > 0 || q.limit > 0) {
+		page := make(map[string]any)
+		for _, c := range checks {
+			name, _ := c["name"].(string)
+			if v, ok := children[name]; ok {
+				page[name] = v
+			}
+		}
+		data["children"] = page
+	}
+	q.pick(checks)
+	data["checks"], data["total"] = checks, total
+	return nil
+}
+
+// writeBody writes v as JSON, or as MessagePack for format
+// application/msgpack.
+func writeBody(w http.ResponseWriter, r *http.Request, format string, code int, v any, indent bool) {
+	var (
+		data []byte
+		err  error
+	)
+	if format == "application/msgpack" {
+		data, err = marshalMsgpack(v)
+	} else if indent {
+		data, err = json.MarshalIndent(v, "", "  ")
+	} else {
+		data, err = json.Marshal(v)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	contentType := "application/msgpack"
+	if format != contentType {
+		contentType = "application/json"
+		data = append(data, '\n')
+	}
+	writeEncoded(w, r, code, contentType, data)
+}
+
+// writeEncoded writes data gzipped when the client accepts it.
+func writeEncoded(w http.ResponseWriter, r *http.Request, code int, contentType string, data []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Add("Vary", "Accept-Encoding")
+	if r == nil || !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.WriteHeader(code)
+		_, _ = w.Write(data)
+		return
+	}
+	w.Header().Set("Content-Encoding", "gzip")
+	w.WriteHeader(code)
+	gz := gzip.NewWriter(w)
+	_, _ = gz.Write(data)
+	_ = gz.Close()
+}
@@ -0,0 +1,56 @@
+package simplehealth
+
+import (
+	"fmt"
+	"slices"
+)
+
+// RemoveCheck removes the check called name and forgets its state. It is
+// safe to call while Start or a handler is running, a run in progress still
+// finishes with the checks it started with. It returns an error when there is
+// no such check.
+func (s *SimpleHealth) RemoveCheck(name string) error {
+	s.checksMu.Lock()
+	i := slices.IndexFunc(s.checks, func(c check) bool { return c.name == name })
+	if i < 0 {
+		s.checksMu.Unlock()
+		return fmt.Errorf("%s: no such check", name)
+	}
+	s.checks = slices.Delete(slices.Clone(s.checks), i, i+1)
+	s.checksMu.Unlock()
+
+	s.mu.Lock()
+	delete(s.unhealthy, name)
+	delete(s.availability, name)
+	s.mu.Unlock()
+	return nil
+}
+
+// ReplaceCheck swaps the check called name for c, keeping its position and
+// state, e.g. when service discovery changes the address it probes. A c that
+// fails its Validate is rejected. Like RemoveCheck it is safe to call at any
+// time.
+func (s *SimpleHealth) ReplaceCheck(name string, c Checker, opts ...CheckOption) error {
+	if c == nil {
+		return fmt.Errorf("%s: nil check", name)
+	}
+	// build the check on a scratch instance to share the registration logic
+	var scratch SimpleHealth
+	scratch.addChecker(name, c, opts...)
+	replacement := scratch.checks[0]
+	if replacement.validate != nil {
+		if err := replacement.validate(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	s.checksMu.Lock()
+	defer s.checksMu.Unlock()
+	i := slices.IndexFunc(s.checks, func(c check) bool { return c.name == name })
+	if i < 0 {
+		return fmt.Errorf("%s: no such check", name)
+	}
+	s.checks = slices.Clone(s.checks)
+	s.checks[i] = replacement
+	return nil
+}
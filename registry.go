@@ -0,0 +1,28 @@
+package simplehealth
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// Default is the SimpleHealth behind Register and DefaultHandler, so packages
+// can add their checks at init time without passing a *SimpleHealth around.
+var Default = NewSimpleHealth()
+
+// Register adds check to Default under name. Like AddCheck it is meant for
+// setup, e.g. from init functions, not for use while serving. Registering
+// one of the default checks, e.g. Register("disk", CheckDisk), replaces the
+// default instead of running it twice.
+func Register(name string, check func() error, opts ...CheckOption) {
+	for _, d := range defaultChecks {
+		if reflect.ValueOf(d).Pointer() == reflect.ValueOf(check).Pointer() {
+			_ = Default.RemoveCheck(funcName(d))
+		}
+	}
+	Default.addFunc(name, check, opts...)
+}
+
+// DefaultHandler serves Default.
+func DefaultHandler(w http.ResponseWriter, r *http.Request) {
+	Default.Handler(w, r)
+}
@@ -0,0 +1,14 @@
+package simplehealth
+
+import "time"
+
+// WithRetries reruns a failing check up to n times within the same run
+// before it counts as failed, waiting backoff before the first retry and
+// doubling the wait after each one. Retries stop at the deadline of
+// WithRunTimeout.
+func WithRetries(n int, backoff time.Duration) CheckOption {
+	return func(c *check) {
+		c.retries = n
+		c.backoff = backoff
+	}
+}
@@ -0,0 +1,64 @@
+package simplehealth
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// RunMode decides what happens when a run starts while another one is in
+// progress, e.g. when a check occasionally takes longer than the probe
+// interval.
+type RunMode int
+
+const (
+	// RunParallel lets runs overlap, the default.
+	RunParallel RunMode = iota
+	// RunQueue waits for the run in progress to finish before running.
+	RunQueue
+	// RunSkip does not run but returns the results of the last full run.
+	// Without one, and for runs with ?tags, it waits like RunQueue.
+	RunSkip
+)
+
+// WithRunMode serializes runs so slow checks cannot pile up. The JSON
+// output has "in_progress": true while a run is in progress.
+func WithRunMode(m RunMode) Option {
+	return func(s *SimpleHealth) {
+		s.runMode = m
+	}
+}
+
+type runGate struct {
+	mu      sync.Mutex // held by the run in progress unless RunParallel
+	running atomic.Int32
+	last    atomic.Pointer[[]CheckResult]
+}
+
+func (s *SimpleHealth) runTagged(ctx context.Context, tags []string) []CheckResult {
+	switch {
+	case s.runMode == RunSkip && len(tags) == 0:
+		if !s.runs.mu.TryLock() {
+			if last := s.runs.last.Load(); last != nil {
+				return *last
+			}
+			s.runs.mu.Lock()
+		}
+		defer s.runs.mu.Unlock()
+	case s.runMode != RunParallel:
+		s.runs.mu.Lock()
+		defer s.runs.mu.Unlock()
+	}
+
+	s.runs.running.Add(1)
+	defer s.runs.running.Add(-1)
+	results := s.runChecks(ctx, tags)
+	if len(tags) == 0 {
+		s.runs.last.Store(&results)
+	}
+	return results
+}
+
+func (s *SimpleHealth) inProgress() bool {
+	return s.runs.running.Load() > 0
+}
@@ -0,0 +1,35 @@
+package simplehealth
+
+import "sync"
+
+// WithSampling runs an expensive check only every n runs and reports its last
+// result in between. The Timestamp of the result tells how fresh it is.
+func WithSampling(n int) CheckOption {
+	return func(c *check) {
+		c.sampling = &sampling{every: n}
+	}
+}
+
+type sampling struct {
+	mu    sync.Mutex
+	every int
+	runs  int
+	last  *CheckResult
+}
+
+// cached returns the last result unless the check is due.
+func (s *sampling) cached() (CheckResult, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs++
+	if s.last == nil || s.every <= 1 || (s.runs-1)%s.every == 0 {
+		return CheckResult{}, false
+	}
+	return *s.last, true
+}
+
+func (s *sampling) store(r CheckResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = &r
+}
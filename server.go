@@ -0,0 +1,107 @@
+package simplehealth
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+// WithTLS makes ListenAndServe serve HTTPS with the given PEM files.
+func WithTLS(certFile, keyFile string) Option {
+	return func(s *SimpleHealth) {
+		s.tlsCert, s.tlsKey = certFile, keyFile
+	}
+}
+
+// WithSocketMode sets the permissions of the unix socket of ListenAndServe,
+// e.g. 0o660 so only the group of a local proxy can connect. The default is
+// 0o666 minus the umask.
+func WithSocketMode(mode fs.FileMode) Option {
+	return func(s *SimpleHealth) {
+		s.socketMode = mode
+	}
+}
+
+// ListenAndServe serves Handler on addr, HistoryHandler under /history and
+// CapacityHandler under /capacity, until ctx is done. It then stops accepting connections and waits up to 10
+// seconds for running requests. A clean shutdown returns nil. An addr like
+// "unix:/run/simplehealth.sock" listens on a unix socket instead, replacing
+// a stale socket file. Under systemd socket activation the passed socket is
+// used and addr ignored, and READY=1 is sent once listening.
+func (s *SimpleHealth) ListenAndServe(ctx context.Context, addr string) error {
+	ln, err := s.listen(addr)
+	if err != nil {
+		return err
+	}
+	if err := sdNotify("READY=1"); err != nil && s.logger != nil {
+		s.logger.Error("cannot notify systemd", "error", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.Handler)
+	mux.HandleFunc("/history", s.HistoryHandler)
+	mux.HandleFunc("/capacity", s.CapacityHandler)
+
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      30*time.Second + s.runTimeout,
+		IdleTimeout:       time.Minute,
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		if s.tlsCert != "" {
+			errc <- srv.ServeTLS(ln, s.tlsCert, s.tlsKey)
+		} else {
+			errc <- srv.Serve(ln)
+		}
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *SimpleHealth) listen(addr string) (net.Listener, error) {
+	if ln, err := activatedListener(); ln != nil || err != nil {
+		return ln, err
+	}
+	path, ok := strings.CutPrefix(addr, "unix:")
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&fs.ModeSocket != 0 {
+		_ = os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if s.socketMode != 0 {
+		if err := os.Chmod(path, s.socketMode); err != nil {
+			ln.Close()
+			return nil, err
+		}
+	}
+	return ln, nil
+}
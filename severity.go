@@ -0,0 +1,41 @@
+package simplehealth
+
+// Severity ranks how urgent a failing check is. The zero value is
+// SeverityCritical, so checks page unless configured otherwise.
+type Severity int
+
+const (
+	SeverityCritical Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	default:
+		return "critical"
+	}
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func WithSeverity(s Severity) CheckOption {
+	return func(c *check) {
+		c.severity = s
+	}
+}
+
+// severityOf returns the most severe failure, SeverityInfo when nothing fails.
+func severityOf(failures []CheckResult) Severity {
+	s := SeverityInfo
+	for _, f := range failures {
+		s = min(s, f.Severity)
+	}
+	return s
+}
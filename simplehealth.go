@@ -1,29 +1,154 @@
 package simplehealth
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"net/http"
-	"os"
-	"path/filepath"
+	"net/netip"
+	"reflect"
 	"runtime"
+	"slices"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 
 	"github.com/shirou/gopsutil/v3/disk"
-	"github.com/shirou/gopsutil/v3/load"
-	"github.com/shirou/gopsutil/v3/process"
 )
 
 const (
 	maxLoad          = 0.8
 	maxOpenFilesPerc = 0.9
 	maxDiskPerc      = 0.9
+	maxInodePerc     = 0.9
 )
 
+// MaxHandles is the handle count of a process that the open files checks
+// treat as its limit on Windows, which has no practical one: the kernel
+// allows 2^24 handles, but a process holding more than about 10k almost
+// always leaks them. Set it before the checks run.
+var MaxHandles = 10_000
+
 type SimpleHealth struct {
-	checks []func() error
+	checks    []check
+	children  []*childProbe
+	envelope  Envelope
+	logger    *slog.Logger
+	notifiers []Notifier
+	queues    notifyQueues
+	pings     []string
+	clock     Clock
+	stateFile string
+	zabbix    []ZabbixSender
+	policy    StatusPolicy
+	adaptive  *adaptive
+
+	collectors      []collector
+	historySize     int
+	textfileDir     string
+	skipUnavailable bool
+	concurrency     int
+	softFail        bool
+	runner          heartbeat
+	auth            credentials
+	store           Store
+	allowed         []netip.Prefix
+	runTimeout      time.Duration
+	terse           bool
+	tlsCert         string
+	tlsKey          string
+	socketMode      fs.FileMode
+	watchdog        bool
+	staleAfter      time.Duration
+	failStale       bool
+	tracer          Tracer
+	capacityWeights map[string]float64
+	statsd          []StatsDEmitter
+	pushers         []Pusher
+	runMode         RunMode
+	runs            runGate
+	lowFootprint    bool
+	checkNotifiers  bool
+	// aggregateUnhealthy is the verdict of the status policy on the last
+	// full run, guarded by mu.
+	aggregateUnhealthy bool
+
+	reportCapabilities bool
+
+	// configErrs collects invalid options, they fail every run
+	configErrs []error
+
+	// checksMu guards s.checks, which is replaced rather than modified in
+	// place so runs can keep using the slice they started with
+	checksMu sync.RWMutex
+
+	mu           sync.Mutex
+	unhealthy    map[string]bool
+	availability map[string]*uptime
+	snapshot     snapshot
+}
+
+type Option func(*SimpleHealth)
+
+type CheckOption func(*check)
+
+type check struct {
+	name            string
+	fn              func() (any, error)
+	fnContext       func(context.Context) (any, error)
+	validate        func() error
+	hint            string
+	budget          *budget
+	skipUnavailable bool
+	componentType   string
+	threshold       any
+	retries         int
+	backoff         time.Duration
+	severity        Severity
+	observe         bool
+	dependsOn       []string
+	sampling        *sampling
+	optional        bool
+	softFail        bool
+	tags            []string
+	capacity        string
+}
+
+// CheckResult is the outcome of a single check in a run. Value holds what the
+// check observed, such as the nested response of a child endpoint. Err is
+// also set for results that did not fail, e.g. the reason a check was
+// skipped. Timestamp is when the check ran, which predates the run for
+// sampled checks.
+type CheckResult struct {
+	Name      string
+	Status    Status
+	Severity  Severity
+	Value     any
+	Err       error
+	Hint      string
+	Duration  time.Duration
+	Timestamp time.Time
+
+	componentType string
+	threshold     any
+	capacity      string
+}
+
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+	StatusWarn Status = "warn"
+
+	StatusNotApplicable Status = "not_applicable"
+)
+
+func (r CheckResult) Failed() bool {
+	return r.Status == StatusFail
 }
 
 var defaultChecks = []func() error{
@@ -32,182 +157,553 @@ var defaultChecks = []func() error{
 	CheckLoad,
 }
 
-func NewSimpleHealth() *SimpleHealth {
-	return &SimpleHealth{checks: defaultChecks}
+func NewSimpleHealth(opts ...Option) *SimpleHealth {
+	s := &SimpleHealth{}
+	s.SetChecks(defaultChecks...)
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.store == nil && s.lowFootprint {
+		s.store = discardStore{}
+	} else if s.store == nil {
+		s.store = &MemoryStore{}
+	} else if err := s.restore(); err != nil {
+		s.configErrs = append(s.configErrs, fmt.Errorf("cannot load history: %w", err))
+	}
+	if s.reportCapabilities {
+		s.logCapabilities()
+	}
+	return s
 }
 
-func (s *SimpleHealth) AddCheck(check func() error) {
-	s.checks = append(s.checks, check)
+// WithConcurrency runs at most n checks at the same time, so a run with many
+// I/O heavy checks does not add to the load of a struggling host. By default
+// all checks run in parallel.
+func WithConcurrency(n int) Option {
+	return func(s *SimpleHealth) {
+		s.concurrency = n
+	}
 }
 
-func (s *SimpleHealth) SetChecks(checks ...func() error) {
-	s.checks = checks
+// WithRunTimeout fails the checks that did not finish within d, so a hanging
+// check cannot make the response slower than the caller waits, e.g. the
+// health check timeout of a load balancer. The checks keep running in the
+// background, context aware ones see their context canceled.
+func WithRunTimeout(d time.Duration) Option {
+	return func(s *SimpleHealth) {
+		s.runTimeout = d
+	}
 }
 
-func (s *SimpleHealth) Handler(w http.ResponseWriter, _ *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	errs := s.Run()
-	if len(errs) > 0 {
-		w.WriteHeader(http.StatusInternalServerError)
-		errorMessages := make([]string, len(errs))
-		for i, err := range errs {
-			errorMessages[i] = err.Error()
+// timedOut returns the finished results and fails the others.
+func (s *SimpleHealth) timedOut(checks []check, done []chan struct{}, results []CheckResult) []CheckResult {
+	now := orSystemClock(s.clock).Now()
+	out := make([]CheckResult, len(results))
+	for i, c := range checks {
+		select {
+		case <-done[i]:
+			out[i] = results[i]
+		default:
+			out[i] = CheckResult{Name: c.name, Status: StatusFail, Severity: c.severity, Duration: s.runTimeout, Timestamp: now,
+				Err: fmt.Errorf("did not finish within the run timeout of %s", s.runTimeout)}
 		}
-		data := map[string]any{
-			"status": "MUCHSAD",
-			"errors": errorMessages,
-		}
-		enc := json.NewEncoder(w)
-		enc.SetIndent("", "  ")
-		_ = enc.Encode(data)
-		return
 	}
+	return out
+}
 
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]string{
-		"status": "VERYHAPPY",
-	})
+func (s *SimpleHealth) AddCheck(check func() error, opts ...CheckOption) {
+	s.addFunc(funcName(check), check, opts...)
 }
 
-func (s *SimpleHealth) Run() []error {
-	var errs []error
-	errCh := make(chan error, len(s.checks))
+func (s *SimpleHealth) addFunc(name string, check func() error, opts ...CheckOption) {
+	if b, ok := builtins[reflect.ValueOf(check).Pointer()]; ok {
+		s.AddMeasuredCheck(name, b.measure, append([]CheckOption{withCapacity(b.dimension, b.threshold)}, opts...)...)
+		return
+	}
+	s.addCheck(name, func() (any, error) {
+		return nil, check()
+	}, opts...)
+}
+
+// AddMeasuredCheck adds a check that also reports the value it measured,
+// e.g. a latency or usage fraction, which is kept in the check history.
+func (s *SimpleHealth) AddMeasuredCheck(name string, measure func() (float64, error), opts ...CheckOption) {
+	s.addCheck(name, func() (any, error) {
+		v, err := measure()
+		return v, err
+	}, opts...)
+}
 
-	for _, check := range s.checks {
-		go func(c func() error) {
-			errCh <- c()
-		}(check)
+func (s *SimpleHealth) SetChecks(checks ...func() error) {
+	s.checksMu.Lock()
+	s.checks = nil
+	s.checksMu.Unlock()
+	for _, c := range checks {
+		s.AddCheck(c)
 	}
+}
 
-	for range s.checks {
-		if err := <-errCh; err != nil {
-			errs = append(errs, err)
-		}
+func (s *SimpleHealth) addCheck(name string, fn func() (any, error), opts ...CheckOption) {
+	s.checksMu.Lock()
+	defer s.checksMu.Unlock()
+	c := check{name: s.uniqueName(name), fn: fn}
+	for _, opt := range opts {
+		opt(&c)
 	}
+	s.checks = append(s.checks, c)
+}
 
-	return errs
+func (s *SimpleHealth) checkList() []check {
+	s.checksMu.RLock()
+	defer s.checksMu.RUnlock()
+	return s.checks
 }
 
-func CheckLoad() error {
-	avg, err := load.Avg()
+// uniqueName suffixes name when it is taken, e.g. by two CheckConntrack checks.
+func (s *SimpleHealth) uniqueName(name string) string {
+	unique := name
+	for i := 2; slices.ContainsFunc(s.checks, func(c check) bool { return c.name == unique }); i++ {
+		unique = fmt.Sprintf("%s#%d", name, i)
+	}
+	return unique
+}
+
+// Handler responds with JSON, or with text/plain or text/html when the
+// Accept header prefers those. With ?verbose=0 the JSON only holds the
+// status, with ?verbose=1 it adds the result of every check. With
+// WithBearerToken or WithBasicAuth, requests without credentials get the
+// terse JSON and wrong credentials get 401. Clients outside WithAllowedCIDRs
+// also get the terse JSON. ?fields=, ?offset= and ?limit= select from the
+// verbose checks. Accept: application/msgpack or application/x-protobuf
+// (see simplehealth.proto) switch the encoding.
+func (s *SimpleHealth) Handler(w http.ResponseWriter, r *http.Request) {
+	authorized, sent := s.auth.authorize(r)
+	if !authorized && sent {
+		s.auth.unauthorized(w)
+		return
+	}
+	q, err := parseListQuery(r)
 	if err != nil {
-		return err
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	authorized = authorized && s.allowedClient(r)
+	results := s.results(r)
+	code, status := s.status(results)
+	format, terse, verbose := accepted(r), false, false
+	if authorized {
+		terse, verbose = verbosity(r, s.terse)
+	} else {
+		format, terse = "application/json", true
 	}
-	numCPU := runtime.NumCPU()
-	if got := avg.Load5 / float64(numCPU); got > maxLoad {
-		return fmt.Errorf("high load5 per cpu: %f", got)
+	switch format {
+	case "text/plain":
+		writeText(w, code, results)
+		return
+	case "text/html":
+		writeHTML(w, code, results)
+		return
+	case "application/x-protobuf":
+		writeProtobuf(w, r, code, status, results, terse, verbose)
+		return
+	}
+
+	data := map[string]any{
+		"status": status,
 	}
-	return nil
+	errs := errorsOf(results)
+	if !terse {
+		if len(errs) > 0 {
+			errorMessages := make([]string, len(errs))
+			for i, err := range errs {
+				errorMessages[i] = err.Error()
+			}
+			data["errors"] = errorMessages
+			if hints := hintsOf(results); len(hints) > 0 {
+				data["hints"] = hints
+			}
+		}
+		s.addDetails(data, results, verbose)
+		if verbose && !q.empty() {
+			if err := selectChecks(data, q); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+	writeBody(w, r, format, code, s.envelope.apply(data), len(errs) > 0)
 }
 
-func CheckOpenFiles() error {
-	processes, err := process.Processes()
-	if err != nil {
-		return err
+func (s *SimpleHealth) Run() []error {
+	return errorsOf(s.run())
+}
+
+// Report is the typed outcome of a run, for custom handlers and exporters.
+type Report struct {
+	Healthy bool          `json:"healthy"`
+	Checks  []CheckResult `json:"checks"`
+}
+
+// RunReport is Run with the result of every check. Healthy follows the
+// status policy, like the code of Handler.
+func (s *SimpleHealth) RunReport() Report {
+	return s.report(s.run())
+}
+
+func (s *SimpleHealth) report(results []CheckResult) Report {
+	code, _ := s.status(results)
+	return Report{Healthy: code < 300, Checks: results}
+}
+
+func (s *SimpleHealth) run() []CheckResult {
+	return s.runTagged(context.Background(), nil)
+}
+
+// runChecks runs the checks with one of tags, or all checks without tags.
+// Exports only follow full runs.
+func (s *SimpleHealth) runChecks(ctx context.Context, tags []string) []CheckResult {
+	ctx, span := s.startSpan(ctx, "simplehealth.run")
+	defer span.End()
+
+	var timeout <-chan time.Time
+	if s.runTimeout > 0 {
+		timeout = orSystemClock(s.clock).After(s.runTimeout)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.runTimeout)
+		defer cancel()
 	}
 
-	for _, p := range processes {
-		user, _ := p.Username()
-		name, _ := p.Name()
-		pname := fmt.Sprintf("%d/%s/%s", p.Pid, user, name)
+	checks := s.checkList()
+	if s.runner.active() {
+		checks = append([]check{s.runner.check(orSystemClock(s.clock))}, checks...)
+	}
+	if len(s.collectors) > 0 {
+		checks = append(checks[:len(checks):len(checks)], s.collect(ctx, tags, withTags(checks, tags))...)
+	}
+	for _, c := range s.children {
+		checks = append(checks[:len(checks):len(checks)], check{name: c.Name, fnContext: c.probe, componentType: "component"})
+	}
+	if len(tags) > 0 {
+		checks = withTags(checks, tags)
+	}
+	if len(s.configErrs) > 0 {
+		err := errors.Join(s.configErrs...)
+		checks = append([]check{{name: "config", fn: func() (any, error) { return nil, err }}}, checks...)
+	}
 
-		rlimits, err := p.Rlimit()
-		if err != nil {
-			continue
+	results := make([]CheckResult, len(checks))
+	var sem chan struct{}
+	if s.concurrency > 0 {
+		sem = make(chan struct{}, s.concurrency)
+	}
+	order, index, bad := schedule(checks)
+	done := make([]chan struct{}, len(checks))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	finished := make(chan struct{})
+	go func() {
+		var wg sync.WaitGroup
+		for _, i := range order {
+			c := checks[i]
+			wg.Add(1)
+			c.skipUnavailable = c.skipUnavailable || s.skipUnavailable
+			c.softFail = s.softFail
+			if sem != nil {
+				sem <- struct{}{}
+			}
+			go func() {
+				defer wg.Done()
+				defer close(done[i])
+				if sem != nil {
+					defer func() { <-sem }()
+				}
+				checkCtx, checkSpan := s.startSpan(ctx, c.name)
+				defer func() { endCheckSpan(checkSpan, results[i]) }()
+				if err := bad[i]; err != nil {
+					results[i] = CheckResult{Name: c.name, Status: StatusFail, Severity: c.severity, Err: err}
+				} else if c.optional && s.underLoad() {
+					results[i] = CheckResult{Name: c.name, Status: StatusSkip, Severity: c.severity, Err: errUnderLoad}
+				} else if r, skipped := c.dependencyResult(index, done, results); skipped {
+					results[i] = r
+				} else {
+					results[i] = c.execute(checkCtx)
+				}
+			}()
 		}
+		wg.Wait()
+		close(finished)
+	}()
+	// the checks that are still running keep writing to results
+	out := results
+	select {
+	case <-finished:
+	case <-timeout:
+		out = s.timedOut(checks, done, results)
+	}
 
-		softLimit := rlimits[syscall.RLIMIT_NOFILE].Soft
-		if softLimit <= 0 {
-			// Skip processes with no file limits
-			continue
-		}
+	// the policy decides the verdict every sink reports
+	report := s.report(out)
+	span.SetAttribute("checks", len(out))
+	span.SetAttribute("healthy", report.Healthy)
+
+	s.record(out, len(tags) > 0, report.Healthy)
+	if len(tags) > 0 {
+		return out
+	}
+	s.saveSnapshot(out)
+	s.ping(report)
+	s.writeStateFile(report)
+	s.writeTextfile(report)
+	s.sendZabbix(report)
+	s.sendStatsD(report)
+	s.push(report)
+	s.notifyWatchdog(report)
+	return out
+}
 
-		if softLimit < 1024 && (user == "root" || user == "sshd") {
-			/*
-				dodge an edge case where sshd sometimes has a limit of 1: cat /proc/$(pgrep sshd -n)/limits
-
-				Data Limit                     Soft Limit           Hard Limit           Units
-					Max cpu time              unlimited            unlimited            seconds
-					Max file size             0                    0                    bytes
-					Max data size             unlimited            unlimited            bytes
-					Max stack size            8388608              unlimited            bytes
-					Max core file size        0                    unlimited            bytes
-					Max resident set          unlimited            unlimited            bytes
-					Max processes             0                    0                    processes
-					Max open files            1                    1                    files
-					Max locked memory         8388608              8388608              bytes
-					Max address space         unlimited            unlimited            bytes
-					Max file locks            unlimited            unlimited            locks
-					Max pending signals       62319                62319                signals
-					Max msgqueue size         819200               819200               bytes
-					Max nice priority         0                    0
-					Max realtime priority     0                    0
-					Max realtime timeout      unlimited            unlimited            us
-			*/
+func (c *check) execute(ctx context.Context) CheckResult {
+	fn := func() (any, error) { return c.call(ctx) }
+	if c.budget != nil {
+		fn = func() (any, error) { return c.runWithBudget(ctx) }
+	}
 
-			continue
+	if c.sampling != nil {
+		if r, ok := c.sampling.cached(); ok {
+			return r
 		}
+	}
 
-		cur, err := p.NumFDs()
-		if err != nil || cur == 0 {
-			continue
+	start := time.Now()
+	value, err := fn()
+retries:
+	for i, wait := 0, c.backoff; err != nil && i < c.retries; i, wait = i+1, 2*wait {
+		select {
+		case <-ctx.Done():
+			break retries
+		case <-time.After(wait):
+		}
+		value, err = fn()
+	}
+	r := CheckResult{Name: c.name, Status: StatusPass, Severity: c.severity, Value: value, Err: err, Duration: time.Since(start), Timestamp: start, componentType: c.componentType, threshold: c.threshold, capacity: c.capacity}
+	switch {
+	case err == nil:
+	case c.skipUnavailable && errors.Is(err, ErrUnavailable):
+		r.Status = StatusSkip
+	case c.softFail && errors.Is(err, ErrNotApplicable):
+		r.Status = StatusNotApplicable
+	case errors.Is(err, ErrPartial):
+		r.Status = StatusWarn
+	default:
+		r.Status = StatusFail
+		if c.observe {
+			r.Status = StatusWarn
 		}
+		r.Hint = c.hint
+		if c.hint == "" {
+			r.Hint = hintOf(err)
+		}
+	}
+	if c.sampling != nil {
+		c.sampling.store(r)
+	}
+	return r
+}
+
+// call runs the check with ctx, which only context aware checks see. Without
+// WithSoftFail checks that are not applicable report their fallback.
+func (c *check) call(ctx context.Context) (any, error) {
+	var (
+		value any
+		err   error
+	)
+	if c.fnContext != nil {
+		value, err = c.fnContext(ctx)
+	} else {
+		value, err = c.fn()
+	}
+	if !c.softFail {
+		err = withoutSoftFail(err)
+	}
+	return value, err
+}
 
-		if cur > int32(softLimit) {
-			// cannot happen?!
+func errorsOf(results []CheckResult) []error {
+	var errs []error
+	for _, r := range results {
+		if !r.Failed() {
 			continue
 		}
-
-		usage := float64(cur) / float64(softLimit)
-		if usage > maxOpenFilesPerc {
-			return fmt.Errorf("%s uses %d%% open files, are we growing too fast?", pname, int(usage*100))
+		// report the mounts of a disk check separately
+		if joined, ok := r.Err.(diskErrors); ok {
+			errs = append(errs, joined...)
+		} else {
+			errs = append(errs, r.Err)
 		}
 	}
-	return nil
+	return errs
+}
+
+// funcName names a check after its function, e.g. "CheckDisk" for
+// simplehealth.CheckDisk or for a closure returned by CheckConntrack.
+func funcName(fn any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	name = strings.TrimSuffix(name, "-fm")
+	parts := strings.Split(name, ".")
+	for len(parts) > 2 && strings.HasPrefix(parts[len(parts)-1], "func") {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) > 1 {
+		parts = parts[1:]
+	}
+	return strings.NewReplacer("(*", "", ")", "").Replace(strings.Join(parts, "."))
+}
+
+// builtins lets AddCheck keep the values built-in checks observe and the
+// thresholds they compare them to.
+var builtins = map[uintptr]struct {
+	measure   func() (float64, error)
+	threshold float64
+	dimension string
+}{
+	reflect.ValueOf(CheckLoad).Pointer():      {measureLoad, maxLoad, "load"},
+	reflect.ValueOf(CheckOpenFiles).Pointer(): {measureOpenFiles, maxOpenFilesPerc, "open_files"},
+	reflect.ValueOf(CheckDisk).Pointer():      {measureDisk, maxDiskPerc, "disk"},
+}
+
+// WithThreshold documents the limit a check compares its value to, it is
+// reported next to the observed value in verbose output.
+func WithThreshold(v any) CheckOption {
+	return func(c *check) {
+		c.threshold = v
+	}
+}
+
+func CheckLoad() error {
+	_, err := measureLoad()
+	return err
+}
+
+func CheckOpenFiles() error {
+	_, err := measureOpenFiles()
+	return err
+}
+
+func openFilesError(pname string, usage float64) error {
+	return withHint(fmt.Errorf("%s uses %d%% open files, are we growing too fast?", pname, int(usage*100)), hintOpenFiles)
+}
+
+// DiskCheck fails when a mount crosses MaxBytesPerc of its space or
+// MaxInodesPerc of its inodes, reporting both as separate *DiskFullError
+// values. Thresholds are fractions, zero disables a dimension. A mount that
+// does not answer within 2 seconds, e.g. a dead NFS share, fails with
+// ErrUnresponsive instead of hanging the run.
+type DiskCheck struct {
+	MaxBytesPerc  float64
+	MaxInodesPerc float64
+	// Mounts decides which mounts are checked, the first matching rule
+	// wins. Nil uses DefaultMountRules.
+	Mounts []MountRule
+}
+
+func NewDiskCheck() *DiskCheck {
+	return &DiskCheck{MaxBytesPerc: maxDiskPerc, MaxInodesPerc: maxInodePerc}
+}
+
+type DiskFullError struct {
+	Mountpoint string
+	Resource   string // "bytes" or "inodes"
+	Percent    float64
+}
+
+func (e *DiskFullError) Error() string {
+	return fmt.Sprintf("disk %s %s %.0f%% full", e.Mountpoint, e.Resource, e.Percent)
+}
+
+// diskErrors joins the errors of the mounts of a disk check, which are
+// reported separately.
+type diskErrors []error
+
+func (e diskErrors) Error() string   { return errors.Join(e...).Error() }
+func (e diskErrors) Unwrap() []error { return e }
+
+func joinDiskErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return diskErrors(errs)
 }
 
 func CheckDisk() error {
+	return NewDiskCheck().Check()
+}
+
+func measureDisk() (float64, error) {
+	return NewDiskCheck().Measure()
+}
+
+func (c *DiskCheck) skip(part disk.PartitionStat) bool {
+	if c.Mounts == nil {
+		return skipPartition(part)
+	}
+	return skipMount(c.Mounts, part)
+}
+
+func (c *DiskCheck) Check() error {
+	_, err := c.Measure()
+	return err
+}
+
+// Measure returns the highest fraction of bytes used on any mount.
+func (c *DiskCheck) Measure() (float64, error) {
 	parts, err := disk.Partitions(false)
 	if err != nil {
-		return err
+		return 0, unavailable("partitions", err)
 	}
 
+	var highest float64
+	var errs []error
 	for _, part := range parts {
-		if strings.Contains(part.Device, "loop") || strings.Contains(part.Mountpoint, "/snap/") ||
-			strings.Contains(part.Mountpoint, "/boot") ||
-			strings.Contains(part.Device, "devfs") {
+		if c.skip(part) {
 			continue
 		}
 
-		usage, err := disk.Usage(part.Mountpoint)
+		usage, err := mountUsage(part.Mountpoint)
+		if errors.Is(err, ErrUnresponsive) {
+			errs = append(errs, err)
+			continue
+		}
 		if err != nil {
 			continue
 		}
 
 		// log.Printf("Disk %s bytes is %.0f%% full\n", part.Mountpoint, usage.UsedPercent)
-		if usage.UsedPercent >= 100*maxDiskPerc {
-			return fmt.Errorf("disk %s bytes %.0f%% full", part.Mountpoint, usage.UsedPercent)
+		highest = max(highest, usage.UsedPercent/100)
+		if c.MaxBytesPerc > 0 && usage.UsedPercent >= 100*c.MaxBytesPerc {
+			errs = append(errs, &DiskFullError{Mountpoint: part.Mountpoint, Resource: "bytes", Percent: usage.UsedPercent})
 		}
 
-		statvfs := syscall.Statfs_t{}
-		err = syscall.Statfs(part.Mountpoint, &statvfs)
-		if err != nil {
-			continue
-		}
-		if statvfs.Files > 0 {
-			percInodes := 100.0 * float64(statvfs.Files-statvfs.Ffree) / float64(statvfs.Files)
+		// InodesTotal is 0 where there are no inodes, e.g. on Windows
+		if usage.InodesTotal > 0 {
+			percInodes := usage.InodesUsedPercent
 			// log.Printf("Disk %s inodes is %.0f%% full\n", part.Mountpoint, percInodes)
-			if percInodes >= 100*maxDiskPerc {
-				return fmt.Errorf("disk %s inodes %.0f%% full", part.Mountpoint, percInodes)
+			if c.MaxInodesPerc > 0 && percInodes >= 100*c.MaxInodesPerc {
+				errs = append(errs, &DiskFullError{Mountpoint: part.Mountpoint, Resource: "inodes", Percent: percInodes})
 			}
 		}
 	}
-	return nil
+	return highest, joinDiskErrors(errs)
 }
 
 func AgeOfNewestFile(glob string) (float64, error) {
-	files, err := filepath.Glob(glob)
+	return AgeOfNewestFileFS(nil, nil, glob)
+}
+
+// AgeOfNewestFileFS is AgeOfNewestFile within fsys, e.g. an embed.FS or
+// fstest.MapFS, relative to clock. Nil values use the OS and system clock.
+func AgeOfNewestFileFS(fsys fs.FS, clock Clock, glob string) (float64, error) {
+	files, err := globFiles(fsys, glob)
 	if err != nil {
 		return 0, err
 	}
@@ -217,15 +713,10 @@ func AgeOfNewestFile(glob string) (float64, error) {
 
 	var newest time.Time
 	for _, f := range files {
-		info, err := os.Stat(f)
-		if err != nil {
-			return 0, err
-		}
-
-		if info.ModTime().After(newest) {
-			newest = info.ModTime()
+		if f.modTime.After(newest) {
+			newest = f.modTime
 		}
 	}
 
-	return time.Since(newest).Hours() / 24, nil
+	return orSystemClock(clock).Now().Sub(newest).Hours() / 24, nil
 }
@@ -0,0 +1,39 @@
+package simplehealth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		check    func() error
+		req      *http.Request
+		wantCode int
+		wantBody string
+	}{
+		{"healthy", func() error { return nil }, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, `"VERYHAPPY"`},
+		{"unhealthy", func() error { return errors.New("down") }, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusInternalServerError, `"MUCHSAD"`},
+		{"verbose", func() error { return nil }, httptest.NewRequest(http.MethodGet, "/?verbose=1", nil), http.StatusOK, `"checks"`},
+		{"request without URL", func() error { return nil }, &http.Request{Method: http.MethodGet, Header: http.Header{}}, http.StatusOK, `"VERYHAPPY"`},
+		{"bad limit", func() error { return nil }, httptest.NewRequest(http.MethodGet, "/?limit=x", nil), http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSimpleHealth()
+			s.SetChecks(tt.check)
+			w := httptest.NewRecorder()
+			s.Handler(w, tt.req)
+			if w.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", w.Code, tt.wantCode)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", w.Body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package simplehealth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+var smartctlPath = "smartctl"
+
+// smartctlTimeout bounds a smartctl call, which can hang on a failing disk.
+const smartctlTimeout = 30 * time.Second
+
+type smartctlOutput struct {
+	Smartctl struct {
+		Messages []struct {
+			String   string `json:"string"`
+			Severity string `json:"severity"`
+		} `json:"messages"`
+	} `json:"smartctl"`
+	Devices []struct {
+		Name string `json:"name"`
+	} `json:"devices"`
+	SmartStatus *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	ATAAttributes struct {
+		Table []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+			Raw  struct {
+				Value int64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeLog *struct {
+		CriticalWarning int   `json:"critical_warning"`
+		MediaErrors     int64 `json:"media_errors"`
+	} `json:"nvme_smart_health_information_log"`
+}
+
+// ATA attributes that indicate a disk is going bad when non-zero.
+var smartBadAttributes = map[int]bool{
+	5:   true, // Reallocated_Sector_Ct
+	197: true, // Current_Pending_Sector
+	198: true, // Offline_Uncorrectable
+}
+
+// CheckSMART runs smartctl (7.0+ for JSON output) against the given devices,
+// or all devices smartctl can find, and fails on a failing overall health
+// assessment, reallocated or pending sectors, or NVMe media errors.
+func CheckSMART(devices ...string) ValidatedCheck {
+	return validated(func() error {
+		devs := devices
+		if len(devs) == 0 {
+			out, err := smartctl("--scan")
+			if err != nil {
+				return err
+			}
+			for _, d := range out.Devices {
+				devs = append(devs, d.Name)
+			}
+		}
+
+		for _, dev := range devs {
+			out, err := smartctl("-H", "-A", dev)
+			if err != nil {
+				return err
+			}
+			if err := out.check(dev); err != nil {
+				return withHint(err, fmt.Sprintf(hintSMART, dev))
+			}
+		}
+		return nil
+	}, func() error {
+		if _, err := exec.LookPath(smartctlPath); err != nil {
+			return err
+		}
+		for _, dev := range devices {
+			if err := validatePath(dev); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func smartctl(args ...string) (*smartctlOutput, error) {
+	// smartctl uses its exit status as a bitmask of disk problems, so the
+	// JSON output is authoritative and exec errors only matter without it.
+	ctx, cancel := context.WithTimeout(context.Background(), smartctlTimeout)
+	defer cancel()
+	raw, execErr := exec.CommandContext(ctx, smartctlPath, append([]string{"-j"}, args...)...).Output()
+	var out smartctlOutput
+	if err := json.Unmarshal(raw, &out); err != nil {
+		if execErr != nil {
+			return nil, fmt.Errorf("smartctl %s: %w", strings.Join(args, " "), execErr)
+		}
+		return nil, fmt.Errorf("smartctl %s: %w", strings.Join(args, " "), err)
+	}
+	return &out, nil
+}
+
+func (out *smartctlOutput) check(dev string) error {
+	if out.SmartStatus == nil {
+		for _, m := range out.Smartctl.Messages {
+			if m.Severity == "error" {
+				return fmt.Errorf("smart %s: %s", dev, m.String)
+			}
+		}
+		// no SMART support (e.g. virtual disks), nothing to assess
+		return nil
+	}
+	if !out.SmartStatus.Passed {
+		return fmt.Errorf("smart %s: overall health assessment failed", dev)
+	}
+	for _, attr := range out.ATAAttributes.Table {
+		if smartBadAttributes[attr.ID] && attr.Raw.Value > 0 {
+			return fmt.Errorf("smart %s: %s is %d", dev, attr.Name, attr.Raw.Value)
+		}
+	}
+	if nvme := out.NVMeLog; nvme != nil {
+		if nvme.CriticalWarning != 0 {
+			return fmt.Errorf("smart %s: nvme critical warning 0x%x", dev, nvme.CriticalWarning)
+		}
+		if nvme.MediaErrors > 0 {
+			return fmt.Errorf("smart %s: %d nvme media errors", dev, nvme.MediaErrors)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package simplehealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// ErrStale marks results that are older than WithCachedResults allows.
+var ErrStale = errors.New("stale results")
+
+// WithCachedResults makes the handlers serve the results of the last full
+// run, e.g. of Start, instead of running the checks per request. Once they
+// are older than staleAfter the response is degraded with a warning, or
+// fails with WithFailStale, so a stuck runner cannot report healthy forever.
+// Until the first run and with ?tags the checks run per request.
+func WithCachedResults(staleAfter time.Duration) Option {
+	return func(s *SimpleHealth) {
+		s.staleAfter = staleAfter
+	}
+}
+
+// WithFailStale fails stale cached results instead of warning.
+func WithFailStale() Option {
+	return func(s *SimpleHealth) {
+		s.failStale = true
+	}
+}
+
+type snapshot struct {
+	time    time.Time
+	results []CheckResult
+}
+
+func (s *SimpleHealth) saveSnapshot(results []CheckResult) {
+	if s.staleAfter == 0 {
+		return
+	}
+	now := orSystemClock(s.clock).Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshot = snapshot{time: now, results: results}
+}
+
+// results returns the cached results for r if there are any, else runs the
+// checks.
+func (s *SimpleHealth) results(r *http.Request) []CheckResult {
+	ctx, tags := context.Background(), tagsOf(r)
+	if r != nil {
+		// a client hanging up must not fail, record and notify the run
+		ctx = context.WithoutCancel(r.Context())
+	}
+	if s.staleAfter == 0 || len(tags) > 0 {
+		return s.runTagged(ctx, tags)
+	}
+	s.mu.Lock()
+	snap := s.snapshot
+	s.mu.Unlock()
+	if snap.results == nil {
+		return s.runTagged(ctx, nil)
+	}
+
+	now := orSystemClock(s.clock).Now()
+	age := now.Sub(snap.time)
+	if age <= s.staleAfter {
+		return snap.results
+	}
+	stale := CheckResult{Name: "snapshot", Status: StatusWarn, Value: age.Seconds(), Timestamp: now,
+		Err: fmt.Errorf("%w: last run finished %s ago, stale after %s", ErrStale, age.Round(time.Millisecond), s.staleAfter)}
+	if s.failStale {
+		stale.Status = StatusFail
+	}
+	return append(slices.Clip(snap.results), stale)
+}
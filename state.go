@@ -0,0 +1,167 @@
+package simplehealth
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"time"
+)
+
+// historySize is the default number of past results kept per check.
+const historySize = 10
+
+// Sample is a past result of a check.
+type Sample struct {
+	Time   time.Time `json:"time"`
+	Status Status    `json:"status,omitempty"`
+	OK     bool      `json:"ok"`
+	Value  any       `json:"value,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// WithLogger logs the outcome and duration of every check, and state
+// transitions of single checks and of the aggregate, as structured events.
+func WithLogger(l *slog.Logger) Option {
+	return func(s *SimpleHealth) {
+		s.logger = l
+	}
+}
+
+// record tracks per-check state between runs, checks never seen count as
+// healthy. Partial runs leave the aggregate and unrun checks alone.
+func (s *SimpleHealth) record(results []CheckResult, partial, healthy bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := orSystemClock(s.clock).Now()
+
+	prev := s.unhealthy
+	s.unhealthy = make(map[string]bool)
+	if partial {
+		maps.Copy(s.unhealthy, prev)
+		for _, r := range results {
+			delete(s.unhealthy, r.Name)
+		}
+	}
+	samples := make(map[string]Sample, len(results))
+	for _, r := range results {
+		failed := r.Failed()
+		observed := r.Status == StatusWarn && !errors.Is(r.Err, ErrPartial)
+		sample := Sample{Time: now, Status: r.Status, OK: !failed && !observed, Value: r.Value}
+		if !sample.OK {
+			sample.Error = r.Err.Error()
+		}
+		samples[r.Name] = sample
+	}
+	// saved before the events are built, which include the history
+	if err := saveAll(s.store, samples, s.historyLen()); err != nil && s.logger != nil {
+		s.logger.Error("cannot save history", "error", err)
+	}
+
+	var changes []CheckEvent
+	for _, r := range results {
+		failed := r.Failed()
+		if failed {
+			s.unhealthy[r.Name] = true
+		}
+		observed := r.Status == StatusWarn && !errors.Is(r.Err, ErrPartial)
+		sample := samples[r.Name]
+		if r.Status != StatusSkip && r.Status != StatusNotApplicable && !s.lowFootprint {
+			s.countUptime(r.Name, sample.OK, now)
+		}
+		if failed != prev[r.Name] && s.checkNotifiers {
+			changes = append(changes, s.newCheckEvent(r, prev[r.Name], now))
+		}
+
+		if s.logger == nil {
+			continue
+		}
+		switch {
+		case failed:
+			s.logger.Warn("check failed", "check", r.Name, "duration", r.Duration, "error", r.Err)
+		case observed:
+			s.logger.Warn("observed check failed", "check", r.Name, "duration", r.Duration, "error", r.Err)
+		default:
+			s.logger.Debug("check passed", "check", r.Name, "duration", r.Duration)
+		}
+		if failed != prev[r.Name] {
+			s.logger.Info("check state changed", "check", r.Name, "from", stateName(!prev[r.Name]), "to", stateName(!failed))
+		}
+	}
+
+	if len(changes) > 0 {
+		s.notifyChecks(changes)
+	}
+
+	// the aggregate follows the status policy over full runs only
+	if partial {
+		return
+	}
+	wasHealthy := !s.aggregateUnhealthy
+	s.aggregateUnhealthy = !healthy
+	if !s.lowFootprint {
+		s.countUptime("", healthy, now)
+	}
+	if healthy == wasHealthy {
+		return
+	}
+	if s.logger != nil {
+		s.logger.Info("health state changed", "from", stateName(wasHealthy), "to", stateName(healthy), "failing", len(s.unhealthy))
+	}
+	if len(s.notifiers) > 0 {
+		s.notify(s.newEvent(healthy, wasHealthy, results))
+	}
+}
+
+// WithHistorySize keeps the last n results per check instead of 10.
+func WithHistorySize(n int) Option {
+	return func(s *SimpleHealth) {
+		s.historySize = n
+	}
+}
+
+func (s *SimpleHealth) historyLen() int {
+	if s.historySize > 0 {
+		return s.historySize
+	}
+	return historySize
+}
+
+// HistoryHandler serves the recent results per check as JSON, oldest first,
+// e.g. mounted at /health/history. ?check=name limits it to one check.
+func (s *SimpleHealth) HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if s.requireAuth(w, r) {
+		return
+	}
+	var (
+		history map[string][]Sample
+		err     error
+	)
+	if only := r.URL.Query().Get("check"); only != "" {
+		var samples []Sample
+		history = make(map[string][]Sample)
+		if samples, err = s.store.History(only); len(samples) > 0 {
+			history[only] = samples
+		}
+	} else {
+		history, err = s.store.Load()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(history)
+}
+
+func stateName(healthy bool) string {
+	if healthy {
+		return "healthy"
+	}
+	return "unhealthy"
+}
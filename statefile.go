@@ -0,0 +1,62 @@
+package simplehealth
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// WithStateFile writes the overall state and the results to path after every
+// run, e.g. /run/simplehealth/state.json, for scripts and MOTD generators
+// that do not speak HTTP. The file is replaced atomically.
+func WithStateFile(path string) Option {
+	return func(s *SimpleHealth) {
+		s.stateFile = path
+	}
+}
+
+func (s *SimpleHealth) writeStateFile(report Report) {
+	if s.stateFile == "" {
+		return
+	}
+	if err := writeStateFile(s.stateFile, report.Healthy, report.Checks, orSystemClock(s.clock)); err != nil && s.logger != nil {
+		s.logger.Error("cannot write state file", "path", s.stateFile, "error", err)
+	}
+}
+
+func writeStateFile(path string, healthy bool, results []CheckResult, clock Clock) error {
+	data, err := json.MarshalIndent(map[string]any{
+		"status": stateName(healthy),
+		"time":   clock.Now(),
+		"checks": results,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// writeFileAtomic replaces path so readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
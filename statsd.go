@@ -0,0 +1,134 @@
+package simplehealth
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// statsdPacketSize keeps datagrams below the usual internet MTU.
+const statsdPacketSize = 1432
+
+// StatsDEmitter sends every run to a statsd agent over UDP: the gauges
+// <prefix>up and, per check, <prefix>check.<check>.up and .value and the
+// timer <prefix>check.<check>.duration. With DogStatsD the check is a tag
+// instead, e.g. simplehealth.check.up:1|g|#check:CheckDisk,env:prod.
+type StatsDEmitter struct {
+	Addr      string // host:port, the port defaults to 8125
+	Prefix    string // defaults to "simplehealth."
+	DogStatsD bool
+	Tags      []string // DogStatsD tags added to every metric
+}
+
+func WithStatsD(e StatsDEmitter) Option {
+	return func(s *SimpleHealth) {
+		s.statsd = append(s.statsd, e)
+	}
+}
+
+func (s *SimpleHealth) sendStatsD(report Report) {
+	for _, e := range s.statsd {
+		go func() {
+			if err := e.Send(report); err != nil && s.logger != nil {
+				s.logger.Error("statsd send failed", "addr", e.Addr, "error", err)
+			}
+		}()
+	}
+}
+
+// Send writes the metrics of report in as few datagrams as fit, up follows
+// report.Healthy.
+func (e StatsDEmitter) Send(report Report) error {
+	addr := e.Addr
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "8125")
+	}
+	conn, err := net.DialTimeout("udp", addr, notifyTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_ = conn.SetWriteDeadline(time.Now().Add(notifyTimeout))
+
+	var packet bytes.Buffer
+	for _, line := range e.lines(report) {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsdPacketSize {
+			if _, err := conn.Write(packet.Bytes()); err != nil {
+				return err
+			}
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	if packet.Len() == 0 {
+		return nil
+	}
+	_, err = conn.Write(packet.Bytes())
+	return err
+}
+
+func (e StatsDEmitter) lines(report Report) []string {
+	prefix := e.Prefix
+	if prefix == "" {
+		prefix = "simplehealth."
+	}
+	var lines []string
+	metric := func(name, check, value, kind string) {
+		var tags []string
+		switch {
+		case check == "":
+		case e.DogStatsD:
+			name = "check." + name
+			tags = append(tags, "check:"+statsdTag(check))
+		default:
+			name = "check." + statsdName(check) + "." + name
+		}
+		if e.DogStatsD {
+			tags = append(tags, e.Tags...)
+		}
+		line := prefix + name + ":" + value + "|" + kind
+		if len(tags) > 0 {
+			line += "|#" + strings.Join(tags, ",")
+		}
+		lines = append(lines, line)
+	}
+
+	for _, r := range report.Checks {
+		ok := "1"
+		if r.Failed() {
+			ok = "0"
+		}
+		metric("up", r.Name, ok, "g")
+		metric("duration", r.Name, strconv.FormatFloat(float64(r.Duration.Microseconds())/1000, 'f', -1, 64), "ms")
+		if v, ok := toFloat(r.Value); ok {
+			metric("value", r.Name, strconv.FormatFloat(v, 'g', -1, 64), "g")
+		}
+	}
+	up := "0"
+	if report.Healthy {
+		up = "1"
+	}
+	metric("up", "", up, "g")
+	return lines
+}
+
+// statsdName makes a check name safe as a metric path segment.
+func statsdName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// statsdTag removes the characters with a meaning in DogStatsD tags.
+func statsdTag(v string) string {
+	return strings.NewReplacer(",", "_", "|", "_", "#", "_", "\n", "_").Replace(v)
+}
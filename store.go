@@ -0,0 +1,255 @@
+package simplehealth
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Store keeps the history of every check, oldest first. The default is a
+// MemoryStore, use a FileStore or SQLiteStore to keep state across restarts.
+// Stores implementing SaveAll(map[string]Sample, int) error save the samples
+// of a run at once instead of calling Save for every check.
+type Store interface {
+	// Save appends sample to the history of check and keeps the last limit.
+	Save(check string, sample Sample, limit int) error
+	// Load returns the history of all checks.
+	Load() (map[string][]Sample, error)
+	// History returns the history of one check.
+	History(check string) ([]Sample, error)
+}
+
+// WithStore keeps the history in st. Checks that failed on their last run
+// before a restart still count as unhealthy, so the restart itself does not
+// notify.
+func WithStore(st Store) Option {
+	return func(s *SimpleHealth) {
+		s.store = st
+	}
+}
+
+// saveAll saves the samples of one run, keyed by check.
+func saveAll(st Store, samples map[string]Sample, limit int) error {
+	if b, ok := st.(interface {
+		SaveAll(map[string]Sample, int) error
+	}); ok {
+		return b.SaveAll(samples, limit)
+	}
+	var errs []error
+	for check, sample := range samples {
+		if err := st.Save(check, sample, limit); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", check, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// restore marks the checks that failed on their last saved run unhealthy.
+func (s *SimpleHealth) restore() error {
+	history, err := s.store.Load()
+	if err != nil {
+		return err
+	}
+	for name, samples := range history {
+		if len(samples) > 0 && samples[len(samples)-1].Status == StatusFail {
+			if s.unhealthy == nil {
+				s.unhealthy = make(map[string]bool)
+			}
+			s.unhealthy[name] = true
+			s.aggregateUnhealthy = true
+		}
+	}
+	return nil
+}
+
+// MemoryStore is a Store in memory. The zero value is ready to use.
+type MemoryStore struct {
+	mu      sync.Mutex
+	samples map[string][]Sample
+}
+
+func (m *MemoryStore) Save(check string, sample Sample, limit int) error {
+	return m.SaveAll(map[string]Sample{check: sample}, limit)
+}
+
+func (m *MemoryStore) SaveAll(samples map[string]Sample, limit int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.samples == nil {
+		m.samples = make(map[string][]Sample)
+	}
+	for check, sample := range samples {
+		h := append(m.samples[check], sample)
+		m.samples[check] = h[max(0, len(h)-limit):]
+	}
+	return nil
+}
+
+func (m *MemoryStore) Load() (map[string][]Sample, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	history := make(map[string][]Sample, len(m.samples))
+	for name, samples := range m.samples {
+		history[name] = slices.Clone(samples)
+	}
+	return history, nil
+}
+
+func (m *MemoryStore) History(check string) ([]Sample, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.samples[check]), nil
+}
+
+// FileStore is a MemoryStore that is written to Path as JSON once per run,
+// replacing the file atomically.
+type FileStore struct {
+	Path string
+
+	once sync.Once
+	err  error
+	mem  MemoryStore
+}
+
+func (f *FileStore) load() error {
+	f.once.Do(func() {
+		data, err := os.ReadFile(f.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		if err == nil {
+			err = json.Unmarshal(data, &f.mem.samples)
+		}
+		f.err = err
+	})
+	return f.err
+}
+
+func (f *FileStore) Save(check string, sample Sample, limit int) error {
+	return f.SaveAll(map[string]Sample{check: sample}, limit)
+}
+
+func (f *FileStore) SaveAll(samples map[string]Sample, limit int) error {
+	if err := f.load(); err != nil {
+		return err
+	}
+	_ = f.mem.SaveAll(samples, limit)
+	history, _ := f.mem.Load()
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(f.Path, data)
+}
+
+func (f *FileStore) Load() (map[string][]Sample, error) {
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	return f.mem.Load()
+}
+
+func (f *FileStore) History(check string) ([]Sample, error) {
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	return f.mem.History(check)
+}
+
+// SQLiteStore is a Store in a SQLite database opened by the caller, e.g.
+// with the modernc.org/sqlite or github.com/mattn/go-sqlite3 driver, so
+// simplehealth does not pick a driver for you.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates the simplehealth_samples table if needed.
+func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS simplehealth_samples (
+		check_name TEXT NOT NULL,
+		time INTEGER NOT NULL, -- unix nanoseconds
+		status TEXT NOT NULL,
+		ok INTEGER NOT NULL,
+		value TEXT,
+		error TEXT
+	)`)
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS simplehealth_samples_check ON simplehealth_samples (check_name, time)`)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (q *SQLiteStore) Save(check string, sample Sample, limit int) error {
+	return q.SaveAll(map[string]Sample{check: sample}, limit)
+}
+
+// SaveAll saves the samples in one transaction.
+func (q *SQLiteStore) SaveAll(samples map[string]Sample, limit int) error {
+	tx, err := q.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for check, sample := range samples {
+		value, err := json.Marshal(sample.Value)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO simplehealth_samples (check_name, time, status, ok, value, error) VALUES (?, ?, ?, ?, ?, ?)`,
+			check, sample.Time.UnixNano(), string(sample.Status), sample.OK, string(value), sample.Error); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM simplehealth_samples WHERE check_name = ? AND rowid NOT IN
+			(SELECT rowid FROM simplehealth_samples WHERE check_name = ? ORDER BY time DESC LIMIT ?)`, check, check, limit); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (q *SQLiteStore) Load() (map[string][]Sample, error) {
+	return q.query(`SELECT check_name, time, status, ok, value, error FROM simplehealth_samples ORDER BY check_name, time`)
+}
+
+func (q *SQLiteStore) History(check string) ([]Sample, error) {
+	history, err := q.query(`SELECT check_name, time, status, ok, value, error FROM simplehealth_samples WHERE check_name = ? ORDER BY time`, check)
+	return history[check], err
+}
+
+func (q *SQLiteStore) query(query string, args ...any) (map[string][]Sample, error) {
+	rows, err := q.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make(map[string][]Sample)
+	for rows.Next() {
+		var (
+			name, status  string
+			ts            int64
+			sample        Sample
+			value, errMsg sql.NullString
+		)
+		if err := rows.Scan(&name, &ts, &status, &sample.OK, &value, &errMsg); err != nil {
+			return nil, err
+		}
+		sample.Time = time.Unix(0, ts)
+		sample.Status = Status(status)
+		sample.Error = errMsg.String
+		if value.Valid {
+			_ = json.Unmarshal([]byte(value.String), &sample.Value)
+		}
+		history[name] = append(history[name], sample)
+	}
+	return history, rows.Err()
+}
@@ -0,0 +1,63 @@
+package simplehealth
+
+import (
+	"net"
+	"os"
+	"strconv"
+)
+
+// WithWatchdog sends WATCHDOG=1 to systemd after every healthy run, so with
+// WatchdogSec= systemd restarts the service once the checks keep failing or
+// stop running. Combine with Start at an interval below half of WatchdogSec.
+func WithWatchdog() Option {
+	return func(s *SimpleHealth) {
+		s.watchdog = true
+	}
+}
+
+func (s *SimpleHealth) notifyWatchdog(report Report) {
+	if !s.watchdog || !report.Healthy {
+		return
+	}
+	if err := sdNotify("WATCHDOG=1"); err != nil && s.logger != nil {
+		s.logger.Error("cannot notify systemd watchdog", "error", err)
+	}
+}
+
+// sdNotify sends state to $NOTIFY_SOCKET and does nothing when systemd did
+// not set it.
+func sdNotify(state string) error {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// activatedListener returns the first socket passed by systemd socket
+// activation, or nil when the process was not socket activated.
+func activatedListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return nil, nil
+	}
+	// child processes must not take the sockets for themselves
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	const listenFDsStart = 3
+	f := os.NewFile(listenFDsStart, "systemd socket")
+	defer f.Close()
+	return net.FileListener(f)
+}
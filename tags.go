@@ -0,0 +1,68 @@
+package simplehealth
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// WithTags tags a check, e.g. "local", "db" or "external", so Handler can run
+// a subset with ?tags=local: only the checks with one of the given tags and
+// the checks they depend on. Untagged checks and children only run without
+// ?tags.
+func WithTags(tags ...string) CheckOption {
+	return func(c *check) {
+		c.tags = append(c.tags, tags...)
+	}
+}
+
+// tagsOf reads ?tags=a,b or ?tags=a&tags=b.
+func tagsOf(r *http.Request) []string {
+	if r == nil || r.URL == nil {
+		return nil
+	}
+	var tags []string
+	for _, v := range r.URL.Query()["tags"] {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				tags = append(tags, t)
+			}
+		}
+	}
+	return tags
+}
+
+// withTags returns the checks with one of tags and their dependencies, in
+// their original order.
+func withTags(checks []check, tags []string) []check {
+	index := make(map[string]int, len(checks))
+	for i, c := range checks {
+		index[c.name] = i
+	}
+	keep := make([]bool, len(checks))
+	var add func(i int)
+	add = func(i int) {
+		if keep[i] {
+			return
+		}
+		keep[i] = true
+		for _, name := range checks[i].dependsOn {
+			if j, ok := index[name]; ok {
+				add(j)
+			}
+		}
+	}
+	for i, c := range checks {
+		if slices.ContainsFunc(c.tags, func(t string) bool { return slices.Contains(tags, t) }) {
+			add(i)
+		}
+	}
+
+	var selected []check
+	for i, c := range checks {
+		if keep[i] {
+			selected = append(selected, c)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,60 @@
+package simplehealth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"text/template"
+)
+
+// TemplateNotifier POSTs the output of Template to URL, to match webhook
+// formats without a dedicated notifier. The template receives a
+// TemplateData; the "json" function quotes a value for use inside JSON, e.g.
+//
+//	{"text": {{json (printf "%s is %s" .Host .Status)}}}
+type TemplateNotifier struct {
+	URL         string
+	Template    *template.Template
+	ContentType string // defaults to application/json
+	Headers     map[string]string
+	Labels      map[string]string
+	Client      *http.Client
+}
+
+// TemplateData is the input of a TemplateNotifier template.
+type TemplateData struct {
+	Event
+	Labels  map[string]string
+	Healthy bool
+}
+
+// NewTemplateNotifier parses tmpl with the template functions of
+// TemplateNotifier.
+func NewTemplateNotifier(url, tmpl string) (*TemplateNotifier, error) {
+	t, err := template.New("notification").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateNotifier{URL: url, Template: t}, nil
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+func (n *TemplateNotifier) Notify(ctx context.Context, e Event) error {
+	var body bytes.Buffer
+	data := TemplateData{Event: e, Labels: n.Labels, Healthy: e.Status == stateName(true)}
+	if err := n.Template.Execute(&body, data); err != nil {
+		return err
+	}
+	contentType := n.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	return post(ctx, n.Client, n.URL, contentType, n.Headers, body.Bytes())
+}
@@ -0,0 +1,65 @@
+package simplehealth
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// WithTextfile writes the results in Prometheus text format to
+// dir/simplehealth.prom after every run, for the node_exporter textfile
+// collector (--collector.textfile.directory).
+func WithTextfile(dir string) Option {
+	return func(s *SimpleHealth) {
+		s.textfileDir = dir
+	}
+}
+
+func (s *SimpleHealth) writeTextfile(report Report) {
+	if s.textfileDir == "" {
+		return
+	}
+	path := filepath.Join(s.textfileDir, "simplehealth.prom")
+	if err := writeFileAtomic(path, s.textfile(report)); err != nil && s.logger != nil {
+		s.logger.Error("cannot write textfile", "path", path, "error", err)
+	}
+}
+
+func (s *SimpleHealth) textfile(report Report) []byte {
+	results := report.Checks
+	var b bytes.Buffer
+	up := 0
+	if report.Healthy {
+		up = 1
+	}
+	fmt.Fprintf(&b, "# HELP simplehealth_up Whether the status policy considers the host healthy.\n# TYPE simplehealth_up gauge\nsimplehealth_up %d\n", up)
+	fmt.Fprintf(&b, "# HELP simplehealth_last_run_timestamp_seconds Time of the last run.\n# TYPE simplehealth_last_run_timestamp_seconds gauge\nsimplehealth_last_run_timestamp_seconds %d\n", orSystemClock(s.clock).Now().Unix())
+
+	b.WriteString("# HELP simplehealth_check_up Whether the check passes, skipped and observed checks count as passing.\n# TYPE simplehealth_check_up gauge\n")
+	for _, r := range results {
+		up := 1
+		if r.Failed() {
+			up = 0
+		}
+		fmt.Fprintf(&b, "simplehealth_check_up{check=%s} %d\n", promLabel(r.Name), up)
+	}
+	b.WriteString("# HELP simplehealth_check_duration_seconds Duration of the check.\n# TYPE simplehealth_check_duration_seconds gauge\n")
+	for _, r := range results {
+		fmt.Fprintf(&b, "simplehealth_check_duration_seconds{check=%s} %s\n", promLabel(r.Name), strconv.FormatFloat(r.Duration.Seconds(), 'g', -1, 64))
+	}
+	b.WriteString("# HELP simplehealth_check_value Value measured by the check.\n# TYPE simplehealth_check_value gauge\n")
+	for _, r := range results {
+		if v, ok := r.Value.(float64); ok {
+			fmt.Fprintf(&b, "simplehealth_check_value{check=%s} %s\n", promLabel(r.Name), strconv.FormatFloat(v, 'g', -1, 64))
+		}
+	}
+	return b.Bytes()
+}
+
+var promEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func promLabel(v string) string {
+	return `"` + promEscaper.Replace(v) + `"`
+}
@@ -0,0 +1,69 @@
+package simplehealth
+
+import "context"
+
+// Tracer starts the spans of WithTracer. It is a small subset of an
+// OpenTelemetry trace.Tracer, so simplehealth does not depend on
+// OpenTelemetry, e.g. with go.opentelemetry.io/otel:
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, simplehealth.Span) {
+//		ctx, span := t.Tracer.Start(ctx, name)
+//		return ctx, otelSpan{span}
+//	}
+//
+//	type otelSpan struct{ trace.Span }
+//
+//	func (s otelSpan) SetAttribute(key string, value any) {
+//		s.SetAttributes(attribute.String(key, fmt.Sprint(value)))
+//	}
+//	func (s otelSpan) RecordError(err error) {
+//		s.Span.RecordError(err)
+//		s.SetStatus(codes.Error, err.Error())
+//	}
+//	func (s otelSpan) End() { s.Span.End() }
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttribute(key string, value any)
+	RecordError(err error)
+	End()
+}
+
+// WithTracer emits a "simplehealth.run" span per run with a child span per
+// check, named after the check, carrying its status and error. Handler
+// parents the run span to the request.
+func WithTracer(t Tracer) Option {
+	return func(s *SimpleHealth) {
+		s.tracer = t
+	}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, any) {}
+func (noopSpan) RecordError(error)        {}
+func (noopSpan) End()                     {}
+
+func (s *SimpleHealth) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if s.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return s.tracer.Start(ctx, name)
+}
+
+// endCheckSpan records the result of a check on its span and ends it.
+func endCheckSpan(span Span, r CheckResult) {
+	span.SetAttribute("check.status", string(r.Status))
+	if r.Value != nil {
+		span.SetAttribute("check.value", r.Value)
+	}
+	if r.Failed() {
+		span.SetAttribute("check.severity", r.Severity.String())
+		span.RecordError(r.Err)
+	}
+	span.End()
+}
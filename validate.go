@@ -0,0 +1,232 @@
+package simplehealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Checker is a configurable check such as *FileCheck or *DiskCheck. Checkers
+// implementing Validate() error are validated by SimpleHealth.Validate, those
+// implementing Measure() (float64, error) also report their measured value.
+type Checker interface {
+	Check() error
+}
+
+// Add adds a Checker, named after its type.
+func (s *SimpleHealth) Add(c Checker, opts ...CheckOption) {
+	switch c := c.(type) {
+	case ValidatedCheck:
+		s.addChecker(c.name, c, opts...)
+		return
+	case CheckFunc:
+		s.AddCheck(c, opts...)
+		return
+	}
+	t := reflect.TypeOf(c)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	s.addChecker(t.Name(), c, opts...)
+}
+
+func (s *SimpleHealth) addChecker(name string, c Checker, opts ...CheckOption) {
+	if v, ok := c.(ValidatedCheck); ok {
+		s.addFunc(name, func() error {
+			return v.check(orSystemClock(s.clock))
+		}, append([]CheckOption{WithValidator(v.validate)}, opts...)...)
+		return
+	}
+	if v, ok := c.(interface{ Validate() error }); ok {
+		opts = append([]CheckOption{WithValidator(v.Validate)}, opts...)
+	}
+	if cc, ok := c.(ContextChecker); ok {
+		opts = append([]CheckOption{withContextFunc(func(ctx context.Context) (any, error) {
+			return nil, cc.CheckContext(ctx)
+		})}, opts...)
+	}
+	switch c := c.(type) {
+	case *LoadCheck:
+		opts = append([]CheckOption{withCapacity("load", c.MaxPerCPU)}, opts...)
+	case *DiskCheck:
+		opts = append([]CheckOption{withCapacity("disk", c.MaxBytesPerc)}, opts...)
+	}
+	if m, ok := c.(interface{ Measure() (float64, error) }); ok {
+		s.AddMeasuredCheck(name, m.Measure, opts...)
+		return
+	}
+	s.addCheck(name, func() (any, error) {
+		return nil, c.Check()
+	}, opts...)
+}
+
+// ValidatedCheck is a check returned by constructors like CheckConntrack,
+// which also validates their arguments. Add names it after its constructor
+// and runs it with the Clock of WithClock.
+type ValidatedCheck struct {
+	name     string
+	check    func(Clock) error
+	validate func() error
+}
+
+func validated(check, validate func() error) ValidatedCheck {
+	return ValidatedCheck{name: funcName(check), check: func(Clock) error { return check() }, validate: validate}
+}
+
+// validatedClock is validated for checks that tell time.
+func validatedClock(check func(Clock) error, validate func() error) ValidatedCheck {
+	return ValidatedCheck{name: funcName(check), check: check, validate: validate}
+}
+
+func (c ValidatedCheck) Check() error {
+	return c.check(systemClock{})
+}
+
+func (c ValidatedCheck) Validate() error {
+	return c.validate()
+}
+
+// WithValidator attaches a configuration check that Validate runs instead of
+// the check itself.
+func WithValidator(validate func() error) CheckOption {
+	return func(c *check) {
+		c.validate = validate
+	}
+}
+
+// Validate reports all configuration errors (invalid options, unparsable
+// child URLs, out of range thresholds, missing paths) without running any
+// check, so they surface at startup instead of at the first probe.
+func (s *SimpleHealth) Validate() error {
+	errs := append([]error(nil), s.configErrs...)
+	for _, c := range s.checkList() {
+		if c.validate == nil {
+			continue
+		}
+		if err := c.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+		}
+	}
+	for _, c := range s.children {
+		if err := c.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("child %s: %w", c.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *childProbe) validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	if c.Socket != "" {
+		if _, err := os.Stat(c.Socket); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *FileCheck) Validate() error {
+	if c.FS != nil {
+		if _, err := fs.Glob(c.FS, c.Glob); err != nil {
+			return fmt.Errorf("glob %q: %w", c.Glob, err)
+		}
+	} else if err := validateGlob(c.Glob); err != nil {
+		return err
+	}
+	return validateFileConstraints(c.MaxAge, c.MinSize, c.MinCount)
+}
+
+func (c *RemoteFileCheck) Validate() error {
+	if c.Lister == nil {
+		return errors.New("no remote lister configured")
+	}
+	if c.Path == "" {
+		return errors.New("no remote path configured")
+	}
+	if _, err := path.Match(c.Pattern, ""); err != nil {
+		return fmt.Errorf("pattern %q: %w", c.Pattern, err)
+	}
+	return validateFileConstraints(c.MaxAge, c.MinSize, c.MinCount)
+}
+
+func (c *DiskCheck) Validate() error {
+	if err := validateFraction("MaxBytesPerc", c.MaxBytesPerc); err != nil {
+		return err
+	}
+	return validateFraction("MaxInodesPerc", c.MaxInodesPerc)
+}
+
+// validateGlob checks the pattern syntax and that the directory before the
+// first wildcard exists.
+func validateGlob(glob string) error {
+	// local globs expand with filepath.Glob, which matches like filepath.Match
+	if _, err := filepath.Match(glob, ""); err != nil {
+		return fmt.Errorf("glob %q: %w", glob, err)
+	}
+	dir := glob
+	for strings.ContainsAny(dir, `*?[\`) {
+		dir = filepath.Dir(dir)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateFileConstraints(maxAge time.Duration, minSize int64, minCount int) error {
+	switch {
+	case maxAge < 0:
+		return fmt.Errorf("MaxAge must not be negative, got %s", maxAge)
+	case minSize < 0:
+		return fmt.Errorf("MinSize must not be negative, got %d", minSize)
+	case minCount < 0:
+		return fmt.Errorf("MinCount must not be negative, got %d", minCount)
+	}
+	return nil
+}
+
+func validateFraction(name string, v float64) error {
+	if v < 0 || v > 1 {
+		return fmt.Errorf("%s must be a fraction between 0 and 1, got %v", name, v)
+	}
+	return nil
+}
+
+func validatePositive(name string, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("%s must be positive, got %s", name, d)
+	}
+	return nil
+}
+
+// validateDir checks that the directory of path exists, for files that may
+// only be created once the service runs.
+func validateDir(path string) error {
+	if path == "" {
+		return errors.New("no path configured")
+	}
+	_, err := os.Stat(filepath.Dir(path))
+	return err
+}
+
+func validatePath(path string) error {
+	if path == "" {
+		return errors.New("no path configured")
+	}
+	_, err := os.Stat(path)
+	return err
+}
@@ -0,0 +1,42 @@
+package simplehealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WaitHealthy runs the checks every interval until the status policy
+// considered them healthy continuously for stable, e.g. as a post-deploy
+// gate. When ctx is done first it returns the failures of the last run.
+func (s *SimpleHealth) WaitHealthy(ctx context.Context, stable, interval time.Duration) error {
+	clock := orSystemClock(s.clock)
+	var since time.Time
+	var last []error
+	for {
+		now := clock.Now()
+		report := s.RunReport()
+		if last = errorsOf(report.Checks); !report.Healthy {
+			since = time.Time{}
+		} else if since.IsZero() {
+			since = now
+		}
+		if !since.IsZero() && now.Sub(since) >= stable {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			switch {
+			case !since.IsZero():
+				return fmt.Errorf("healthy for %s, want %s: %w", now.Sub(since).Round(time.Second), stable, ctx.Err())
+			case len(last) == 0:
+				// e.g. warnings that the status policy does not ignore
+				return fmt.Errorf("%w: unhealthy by the status policy", ctx.Err())
+			}
+			return fmt.Errorf("%w: %w", ctx.Err(), errors.Join(last...))
+		case <-clock.After(interval):
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package simplehealth
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ZabbixSender pushes every run to a Zabbix server or proxy with the sender
+// protocol, as zabbix_sender does. It needs trapper items on Host named
+// simplehealth.up, simplehealth.check[<check>] (1 or 0) and, for measured
+// checks, simplehealth.value[<check>].
+type ZabbixSender struct {
+	Server string // host:port, the port defaults to 10051
+	Host   string // defaults to the hostname
+}
+
+func WithZabbix(z ZabbixSender) Option {
+	return func(s *SimpleHealth) {
+		s.zabbix = append(s.zabbix, z)
+	}
+}
+
+type zabbixItem struct {
+	Host  string `json:"host"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Clock int64  `json:"clock"`
+}
+
+func (s *SimpleHealth) sendZabbix(report Report) {
+	now := orSystemClock(s.clock).Now().Unix()
+	for _, z := range s.zabbix {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+			defer cancel()
+			if err := z.Send(ctx, report, now); err != nil && s.logger != nil {
+				s.logger.Error("zabbix send failed", "server", z.Server, "error", err)
+			}
+		}()
+	}
+}
+
+// Send pushes report with the given unix timestamp, simplehealth.up follows
+// report.Healthy.
+func (z ZabbixSender) Send(ctx context.Context, report Report, clock int64) error {
+	host := z.Host
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	var items []zabbixItem
+	for _, r := range report.Checks {
+		ok := "1"
+		if r.Failed() {
+			ok = "0"
+		}
+		items = append(items, zabbixItem{host, "simplehealth.check[" + zabbixKeyParam(r.Name) + "]", ok, clock})
+		if v, isFloat := r.Value.(float64); isFloat {
+			items = append(items, zabbixItem{host, "simplehealth.value[" + zabbixKeyParam(r.Name) + "]", strconv.FormatFloat(v, 'g', -1, 64), clock})
+		}
+	}
+	up := "0"
+	if report.Healthy {
+		up = "1"
+	}
+	items = append(items, zabbixItem{host, "simplehealth.up", up, clock})
+
+	body, err := json.Marshal(map[string]any{"request": "sender data", "data": items})
+	if err != nil {
+		return err
+	}
+
+	addr := z.Server
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "10051")
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write(zabbixPacket(body)); err != nil {
+		return err
+	}
+	return readZabbixResponse(conn)
+}
+
+// zabbixPacket frames data as "ZBXD", flags 0x01, data length and reserved
+// as little endian uint32s, then the data.
+func zabbixPacket(data []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("ZBXD\x01")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(len(data)))
+	_ = binary.Write(&b, binary.LittleEndian, uint32(0))
+	b.Write(data)
+	return b.Bytes()
+}
+
+func readZabbixResponse(r io.Reader) error {
+	header := make([]byte, 13)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+	if string(header[:4]) != "ZBXD" {
+		return fmt.Errorf("invalid zabbix response header %q", header[:5])
+	}
+	size := binary.LittleEndian.Uint32(header[5:9])
+	if size > 1<<20 {
+		return fmt.Errorf("zabbix response of %d bytes is too large", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return err
+	}
+
+	var resp struct {
+		Response string `json:"response"`
+		Info     string `json:"info"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return err
+	}
+	if resp.Response != "success" {
+		return fmt.Errorf("zabbix: %s %s", resp.Response, resp.Info)
+	}
+	// items without a matching trapper item are silently dropped
+	if !strings.Contains(resp.Info, "failed: 0;") {
+		return fmt.Errorf("zabbix: %s", resp.Info)
+	}
+	return nil
+}
+
+// zabbixKeyParam quotes a key parameter when it contains special characters.
+func zabbixKeyParam(p string) string {
+	if !strings.ContainsAny(p, `,]["`+" ") {
+		return p
+	}
+	return `"` + strings.ReplaceAll(p, `"`, `\"`) + `"`
+}